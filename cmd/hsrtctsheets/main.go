@@ -1,8 +1,11 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -21,7 +24,14 @@ const ENEMIES = "Enemies"
 const ATTACKS = "Attacks"
 const SCENARIOS = "Scenarios"
 const EXTERNAL_BUFFS = "ExternalBuffs"
+const TEAMS = "Teams"
+const SUPPORT_BUFFS = "SupportBuffs"
 const RESULTS = "HSRTCT Results"
+const SIMULATION = "HSRTCT Simulation"
+const TEAM_RESULTS = "HSRTCT Teams"
+
+var rotations = flag.Int("rotations", 10000, "amount of rotations simulated per scenario")
+var seed = flag.Int64("seed", 1, "seed of the crit rolls of the simulation")
 
 var lightcones map[string]hsrtct.LightCone = map[string]hsrtct.LightCone{}
 var characters map[string]hsrtct.Character = map[string]hsrtct.Character{}
@@ -30,8 +40,10 @@ var enemies map[string]hsrtct.Enemy = map[string]hsrtct.Enemy{}
 var attacks map[string]hsrtct.Attack = map[string]hsrtct.Attack{}
 var externalBuffs map[string][]hsrtct.Buff = map[string][]hsrtct.Buff{}
 var scenarios []hsrtct.Scenario = []hsrtct.Scenario{}
+var teams []hsrtct.TeamScenario = []hsrtct.TeamScenario{}
 
 func main() {
+	flag.Parse()
 	f, err := excelize.OpenFile(FILENAME)
 	if err != nil {
 		fmt.Println(err)
@@ -57,8 +69,12 @@ func main() {
 	readAttacks(f)
 	log.Println("[INFO] Reading External Buffs...")
 	readExternalBuffs(f)
+	log.Println("[INFO] Reading Support Buffs...")
+	readSupportBuffs(f)
 	log.Println("[INFO] Reading Scenarios...")
 	readScenarios(f)
+	log.Println("[INFO] Reading Teams...")
+	readTeams(f)
 
 	log.Println("[INFO] calculating...")
 	calcAndWrite()
@@ -100,6 +116,17 @@ func calcAndWrite() {
 	f.SetColWidth(RESULTS, "B", "B", 20)
 	f.SetColWidth(RESULTS, "C", "C", 20)
 	f.SetColStyle(RESULTS, "B", centeredNumberStyle)
+	for i, tag := range hsrtct.AllDotTags() {
+		f.SetCellValue(RESULTS, spreadsheetCoordinate(0, 3+i), string(tag)+" Damage")
+		f.SetColWidth(RESULTS, columnName(3+i), columnName(3+i), 20)
+		f.SetColStyle(RESULTS, columnName(3+i), centeredNumberStyle)
+	}
+	distributionCol := 3 + len(hsrtct.AllDotTags())
+	for i, header := range []string{"Min Damage", "Max Damage", "Damage Std Deviation"} {
+		f.SetCellValue(RESULTS, spreadsheetCoordinate(0, distributionCol+i), header)
+		f.SetColWidth(RESULTS, columnName(distributionCol+i), columnName(distributionCol+i), 20)
+		f.SetColStyle(RESULTS, columnName(distributionCol+i), centeredNumberStyle)
+	}
 
 	for rowIndex, scenario := range scenarios {
 		rowIndex++
@@ -115,7 +142,16 @@ func calcAndWrite() {
 		} else {
 			formattedDmg := strconv.FormatFloat(result.TotalDmg, 'f', 0, 64)
 			log.Println("[INFO] " + scenario.Name + ": " + formattedDmg)
+			warnSpBalance(scenario)
 			f.SetCellValue(RESULTS, spreadsheetCoordinate(rowIndex, 1), formattedDmg)
+			for i, tag := range hsrtct.AllDotTags() {
+				if dotDmg, ok := result.DotDmg[tag]; ok {
+					f.SetCellValue(RESULTS, spreadsheetCoordinate(rowIndex, 3+i), strconv.FormatFloat(dotDmg, 'f', 0, 64))
+				}
+			}
+			f.SetCellValue(RESULTS, spreadsheetCoordinate(rowIndex, distributionCol), strconv.FormatFloat(result.MinDmg, 'f', 0, 64))
+			f.SetCellValue(RESULTS, spreadsheetCoordinate(rowIndex, distributionCol+1), strconv.FormatFloat(result.MaxDmg, 'f', 0, 64))
+			f.SetCellValue(RESULTS, spreadsheetCoordinate(rowIndex, distributionCol+2), strconv.FormatFloat(math.Sqrt(result.DmgVariance), 'f', 0, 64))
 
 			for expIndex, exp := range result.Explanations {
 				f.NewSheet(explanationSheetName)
@@ -127,13 +163,122 @@ func calcAndWrite() {
 		}
 	}
 
+	writeTeams(f, centeredNumberStyle)
+
+	log.Println("[INFO] simulating...")
+	writeSimulation(f, centeredNumberStyle)
+
 	if err := f.SaveAs(RESULT_FILENAME); err != nil {
 		log.Println("[ERROR] failed to save results: " + err.Error())
 		fmt.Println("failed to save results: " + err.Error())
 	}
 }
 
+func writeTeams(f *excelize.File, numberStyle int) {
+	if len(teams) == 0 {
+		return
+	}
+	if _, err := f.NewSheet(TEAM_RESULTS); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	f.SetCellValue(TEAM_RESULTS, "A1", "Team")
+	f.SetCellValue(TEAM_RESULTS, "B1", "Damage")
+	f.SetColWidth(TEAM_RESULTS, "A", "A", 50)
+	f.SetColWidth(TEAM_RESULTS, "B", columnName(2+hsrtct.MaxTeamSize*2), 20)
+	for i := 0; i < hsrtct.MaxTeamSize; i++ {
+		f.SetCellValue(TEAM_RESULTS, spreadsheetCoordinate(0, 2+i*2), fmt.Sprintf("Member %d", i+1))
+		f.SetCellValue(TEAM_RESULTS, spreadsheetCoordinate(0, 3+i*2), fmt.Sprintf("Member %d Damage", i+1))
+		f.SetColStyle(TEAM_RESULTS, columnName(3+i*2), numberStyle)
+	}
+	f.SetColStyle(TEAM_RESULTS, "B", numberStyle)
+
+	for i, team := range teams {
+		rowIndex := i + 1
+		f.SetCellValue(TEAM_RESULTS, spreadsheetCoordinate(rowIndex, 0), team.Name)
+		result, err := hsrtct.CalcAvgDmgTeamScenario(team)
+		if err != nil {
+			log.Println("[ERROR] failed to calculate damage for team: " + team.Name + ", " + err.Error())
+			f.SetCellValue(TEAM_RESULTS, spreadsheetCoordinate(rowIndex, 1), "Failed to calculate damage for team: "+team.Name+", "+err.Error())
+			continue
+		}
+		log.Println("[INFO] " + team.Name + ": " + strconv.FormatFloat(result.TotalDmg, 'f', 0, 64))
+		f.SetCellValue(TEAM_RESULTS, spreadsheetCoordinate(rowIndex, 1), strconv.FormatFloat(result.TotalDmg, 'f', 0, 64))
+		for j, member := range team.Members {
+			f.SetCellValue(TEAM_RESULTS, spreadsheetCoordinate(rowIndex, 2+j*2), member.Character.Name)
+			f.SetCellValue(TEAM_RESULTS, spreadsheetCoordinate(rowIndex, 3+j*2), strconv.FormatFloat(result.Members[j].TotalDmg, 'f', 0, 64))
+		}
+	}
+}
+
+// warnSpBalance logs a warning if the scenario spends more skill points than it generates,
+// suggesting more uses of its first basic attack by name
+func warnSpBalance(scenario hsrtct.Scenario) {
+	if scenario.DeriveUltimates {
+		derived, _, err := scenario.WithDerivedUltimates()
+		if err != nil {
+			return
+		}
+		scenario = derived
+	}
+	balance := hsrtct.CalcSpBalance(scenario)
+	if balance >= 0 {
+		return
+	}
+	message := fmt.Sprintf("[WARN] %s spends %.2f more skill points per rotation than it generates", scenario.Name, -balance)
+	var basicAttacks []*hsrtct.Attack
+	for attack := range scenario.Attacks {
+		if attack.DamageTags.Has(hsrtct.Basic) {
+			basicAttacks = append(basicAttacks, attack)
+		}
+	}
+	sort.Slice(basicAttacks, func(i, j int) bool { return basicAttacks[i].Name < basicAttacks[j].Name })
+	for _, attack := range basicAttacks {
+		if basics, err := hsrtct.SuggestBasics(scenario, *attack); err == nil {
+			message += fmt.Sprintf(", it needs %.2f more %s", basics, attack.Name)
+			break
+		}
+	}
+	log.Println(message)
+}
+
+func writeSimulation(f *excelize.File, numberStyle int) {
+	if _, err := f.NewSheet(SIMULATION); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	headers := []string{"Scenario", "Mean", "P5", "P50", "P95", "Min", "Max"}
+	for i, header := range headers {
+		f.SetCellValue(SIMULATION, spreadsheetCoordinate(0, i), header)
+	}
+	f.SetColWidth(SIMULATION, "A", "A", 150)
+	f.SetColWidth(SIMULATION, "B", columnName(len(headers)-1), 20)
+	f.SetColStyle(SIMULATION, "B:"+columnName(len(headers)-1), numberStyle)
+
+	cfg := hsrtct.SimulationConfig{Rotations: *rotations, Seed: *seed}
+	results, errs := hsrtct.SimulateScenarios(scenarios, cfg)
+	for i, scenario := range scenarios {
+		rowIndex := i + 1
+		f.SetCellValue(SIMULATION, spreadsheetCoordinate(rowIndex, 0), scenario.Name)
+		if errs[i] != nil {
+			log.Println("[ERROR] failed to simulate scenario: " + scenario.Name + ", " + errs[i].Error())
+			f.SetCellValue(SIMULATION, spreadsheetCoordinate(rowIndex, 1), "Failed to simulate scenario: "+scenario.Name+", "+errs[i].Error())
+			continue
+		}
+		result := results[i]
+		for j, value := range []float64{result.Mean, result.P5, result.P50, result.P95, result.Min, result.Max} {
+			f.SetCellValue(SIMULATION, spreadsheetCoordinate(rowIndex, 1+j), strconv.FormatFloat(value, 'f', 0, 64))
+		}
+	}
+}
+
 func spreadsheetCoordinate(row, col int) string {
+	return fmt.Sprintf("%s%d", columnName(col), row+1)
+}
+
+func columnName(col int) string {
 	columnLetters := ""
 	col++
 	for col > 0 {
@@ -141,7 +286,7 @@ func spreadsheetCoordinate(row, col int) string {
 		columnLetters = string(rune('A'+col%26)) + columnLetters
 		col /= 26
 	}
-	return fmt.Sprintf("%s%d", columnLetters, row+1)
+	return columnLetters
 }
 
 func mustParseFloat(s string) float64 {
@@ -162,3 +307,14 @@ func mustParseInt(s string) int {
 	}
 	return int(i)
 }
+
+func mustParseBool(s string) bool {
+	if s == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
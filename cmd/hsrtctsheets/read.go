@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"strconv"
+	"strings"
 
 	"github.com/j4rv/hsr-tct/pkg/hsrtct"
 	"github.com/xuri/excelize/v2"
@@ -131,6 +132,20 @@ func readEnemies(f *excelize.File) {
 			}
 		}
 
+		toughness, _ := f.GetCellValue(ENEMIES, spreadsheetCoordinate(i, 22))
+		weaknesses, _ := f.GetCellValue(ENEMIES, spreadsheetCoordinate(i, 23))
+		broken, _ := f.GetCellValue(ENEMIES, spreadsheetCoordinate(i, 24))
+		maxHp, _ := f.GetCellValue(ENEMIES, spreadsheetCoordinate(i, 25))
+		enemy.MaxHp = mustParseFloat(maxHp)
+		enemy.Toughness = mustParseFloat(toughness)
+		enemy.Broken = mustParseBool(broken)
+		for _, weakness := range strings.Split(weaknesses, ",") {
+			weakness = strings.TrimSpace(weakness)
+			if weakness != "" {
+				enemy.Weaknesses = append(enemy.Weaknesses, hsrtct.Element(weakness))
+			}
+		}
+
 		enemies[enemy.Name] = enemy
 	}
 }
@@ -156,7 +171,7 @@ func readAttacks(f *excelize.File) {
 			Multiplier:       mustParseFloat(row[2]),
 			MultiplierSplash: mustParseFloat(row[3]),
 			Element:          hsrtct.Element(row[4]),
-			DamageTag:        hsrtct.DamageTag(row[5]),
+			DamageTags:       hsrtct.ParseDamageTags(row[5]),
 			AttackAOE:        aoe,
 		}
 
@@ -167,6 +182,54 @@ func readAttacks(f *excelize.File) {
 			}
 		}
 
+		toughnessReduction, _ := f.GetCellValue(ATTACKS, spreadsheetCoordinate(i, 27))
+		toughnessReductionSplash, _ := f.GetCellValue(ATTACKS, spreadsheetCoordinate(i, 28))
+		attack.ToughnessReduction = mustParseFloat(toughnessReduction)
+		attack.ToughnessReductionSplash = mustParseFloat(toughnessReductionSplash)
+
+		capScalingStat, _ := f.GetCellValue(ATTACKS, spreadsheetCoordinate(i, 29))
+		capMultiplier, _ := f.GetCellValue(ATTACKS, spreadsheetCoordinate(i, 30))
+		dotStacks, _ := f.GetCellValue(ATTACKS, spreadsheetCoordinate(i, 31))
+		attack.CapScalingStat = hsrtct.Stat(capScalingStat)
+		attack.CapMultiplier = mustParseFloat(capMultiplier)
+		if dotStacks != "" {
+			attack.DotStacks = mustParseInt(dotStacks)
+		}
+
+		hits, _ := f.GetCellValue(ATTACKS, spreadsheetCoordinate(i, 32))
+		firstHitFocused, _ := f.GetCellValue(ATTACKS, spreadsheetCoordinate(i, 33))
+		if hits != "" {
+			attack.Hits = mustParseInt(hits)
+		}
+		attack.FirstHitFocused = mustParseBool(firstHitFocused)
+
+		scalingStat2, _ := f.GetCellValue(ATTACKS, spreadsheetCoordinate(i, 34))
+		multiplier2, _ := f.GetCellValue(ATTACKS, spreadsheetCoordinate(i, 35))
+		multiplierSplash2, _ := f.GetCellValue(ATTACKS, spreadsheetCoordinate(i, 36))
+		flatDamage, _ := f.GetCellValue(ATTACKS, spreadsheetCoordinate(i, 37))
+		if scalingStat2 != "" {
+			attack.Scalings = append(attack.Scalings, hsrtct.Scaling{
+				Stat:             hsrtct.Stat(scalingStat2),
+				Multiplier:       mustParseFloat(multiplier2),
+				MultiplierSplash: mustParseFloat(multiplierSplash2),
+			})
+		}
+		attack.FlatDamage = mustParseFloat(flatDamage)
+
+		energyCost, _ := f.GetCellValue(ATTACKS, spreadsheetCoordinate(i, 38))
+		energyGain, _ := f.GetCellValue(ATTACKS, spreadsheetCoordinate(i, 39))
+		attack.EnergyCost = mustParseFloat(energyCost)
+		attack.EnergyGain = mustParseFloat(energyGain)
+
+		spCost, _ := f.GetCellValue(ATTACKS, spreadsheetCoordinate(i, 40))
+		spGain, _ := f.GetCellValue(ATTACKS, spreadsheetCoordinate(i, 41))
+		if spCost != "" {
+			attack.SpCost = mustParseInt(spCost)
+		}
+		if spGain != "" {
+			attack.SpGain = mustParseInt(spGain)
+		}
+
 		attacks[attack.Name] = attack
 	}
 }
@@ -194,6 +257,46 @@ func readExternalBuffs(f *excelize.File) {
 	}
 }
 
+// readSupportBuffs reads buffs that scale from a support's stats, they are used like external buffs
+func readSupportBuffs(f *excelize.File) {
+	rows, err := f.GetRows(SUPPORT_BUFFS)
+	if err != nil {
+		log.Println("[INFO] no SupportBuffs sheet found, skipping support buffs")
+		return
+	}
+	for i, row := range rows {
+		if i == 0 || len(row) < 4 || row[0] == "" {
+			continue
+		}
+
+		source := &hsrtct.BuffSource{
+			Character:  characters[row[1]],
+			LightCone:  lightcones[row[2]],
+			RelicBuild: relicbuilds[row[3]],
+		}
+		buffs := make([]hsrtct.Buff, 0)
+		for j := 0; j < 4; j++ {
+			col := 4 + j*4
+			ratio, _ := f.GetCellValue(SUPPORT_BUFFS, spreadsheetCoordinate(i, col))
+			if ratio == "" {
+				continue
+			}
+			stat, _ := f.GetCellValue(SUPPORT_BUFFS, spreadsheetCoordinate(i, col+1))
+			sourceStat, _ := f.GetCellValue(SUPPORT_BUFFS, spreadsheetCoordinate(i, col+2))
+			buffCap, _ := f.GetCellValue(SUPPORT_BUFFS, spreadsheetCoordinate(i, col+3))
+			buffs = append(buffs, hsrtct.Buff{
+				Stat:       hsrtct.Stat(stat),
+				SourceStat: hsrtct.Stat(sourceStat),
+				Ratio:      mustParseFloat(ratio),
+				Cap:        mustParseFloat(buffCap),
+				Source:     source,
+			})
+		}
+
+		externalBuffs[row[0]] = append(externalBuffs[row[0]], buffs...)
+	}
+}
+
 func readScenarios(f *excelize.File) {
 	rows, err := f.GetRows(SCENARIOS)
 	if err != nil {
@@ -240,10 +343,80 @@ func readScenarios(f *excelize.File) {
 			scenario.Attacks[attack] = mult
 		}
 
+		deriveUltimates, _ := f.GetCellValue(SCENARIOS, spreadsheetCoordinate(i, 33))
+		kills, _ := f.GetCellValue(SCENARIOS, spreadsheetCoordinate(i, 34))
+		hitsTaken, _ := f.GetCellValue(SCENARIOS, spreadsheetCoordinate(i, 35))
+		energyPerHitTaken, _ := f.GetCellValue(SCENARIOS, spreadsheetCoordinate(i, 36))
+		scenario.DeriveUltimates = mustParseBool(deriveUltimates)
+		scenario.Energy = hsrtct.EnergyModel{
+			Kills:             mustParseFloat(kills),
+			HitsTaken:         mustParseFloat(hitsTaken),
+			EnergyPerHitTaken: mustParseFloat(energyPerHitTaken),
+		}
+
+		teamSpBalance, _ := f.GetCellValue(SCENARIOS, spreadsheetCoordinate(i, 37))
+		scenario.TeamSpBalance = mustParseFloat(teamSpBalance)
+
 		scenarios = append(scenarios, scenario)
 	}
 }
 
+func readTeams(f *excelize.File) {
+	rows, err := f.GetRows(TEAMS)
+	if err != nil {
+		log.Println("[INFO] no Teams sheet found, skipping teams")
+		return
+	}
+	for i, row := range rows {
+		if i == 0 || len(row) < 3 || row[0] == "" || row[1] != "TRUE" {
+			continue
+		}
+
+		team := hsrtct.TeamScenario{Name: row[0]}
+		for j := 0; j < hsrtct.MaxTeamSize; j++ {
+			scenarioName, _ := f.GetCellValue(TEAMS, spreadsheetCoordinate(i, 2+j))
+			if scenarioName == "" {
+				continue
+			}
+			scenario, ok := findScenario(scenarioName)
+			if !ok {
+				panic("failed to read Teams: " + scenarioName + " is not an enabled scenario")
+			}
+			if len(team.Members) == 0 {
+				team.Enemies = scenario.Enemies
+				team.FocusedEnemy = scenario.FocusedEnemy
+			}
+			team.Members = append(team.Members, hsrtct.TeamMember{
+				Character:       scenario.Character,
+				LightCone:       scenario.LightCone,
+				RelicBuild:      scenario.RelicBuild,
+				Attacks:         scenario.Attacks,
+				Energy:          scenario.Energy,
+				DeriveUltimates: scenario.DeriveUltimates,
+			})
+		}
+
+		for j := 0; j < 5; j++ {
+			externalBuff, _ := f.GetCellValue(TEAMS, spreadsheetCoordinate(i, 6+j))
+			if externalBuff == "" {
+				continue
+			}
+			team.TeamBuffs = append(team.TeamBuffs, externalBuffs[externalBuff]...)
+		}
+
+		teams = append(teams, team)
+	}
+}
+
+func findScenario(name string) (hsrtct.Scenario, bool) {
+	for _, scenario := range scenarios {
+		if scenario.Name == name {
+			return scenario, true
+		}
+	}
+	return hsrtct.Scenario{}, false
+}
+
 func readBuff(f *excelize.File, sheetName string, row, col int) (hsrtct.Buff, error) {
 	rawValue, err := f.GetCellValue(sheetName, spreadsheetCoordinate(row, col))
 	if rawValue == "" || err != nil {
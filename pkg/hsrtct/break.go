@@ -0,0 +1,159 @@
+package hsrtct
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidLevel = errors.New("invalid level")
+
+// Base break damage multiplier of each element
+var breakElementMultiplier map[Element]float64 = map[Element]float64{
+	Physical:  2,
+	Fire:      2,
+	Ice:       1,
+	Lightning: 1,
+	Wind:      1.5,
+	Quantum:   0.5,
+	Imaginary: 0.5,
+}
+
+// Level multiplier used by break damage, indexed by character level - 1
+var levelMultiplier []float64 = []float64{
+	54.0000, 58.0000, 62.0000, 67.5264, 70.5094, 73.5228, 76.5660, 79.6385, 82.7395, 85.8684,
+	91.4944, 97.0680, 102.5892, 108.0579, 113.4743, 118.8383, 124.1499, 129.4091, 134.6159, 139.7703,
+	149.3323, 158.8011, 168.1768, 177.4594, 186.6489, 195.7452, 204.7484, 213.6585, 222.4754, 231.1992,
+	246.4276, 261.1810, 275.4733, 289.3179, 302.7275, 315.7144, 328.2905, 340.4671, 352.2554, 363.6658,
+	408.1240, 451.7883, 494.6798, 536.8188, 578.2249, 618.9172, 658.9138, 698.2325, 736.8905, 774.9041,
+	871.0599, 964.8705, 1056.4206, 1145.7910, 1233.0585, 1318.2965, 1401.5750, 1482.9608, 1562.5178, 1640.3068,
+	1752.3215, 1861.9011, 1969.1242, 2074.0659, 2176.7983, 2277.3904, 2375.9085, 2472.4160, 2566.9739, 2659.6406,
+	2780.3044, 2898.6022, 3014.6029, 3128.3729, 3239.9758, 3349.4730, 3456.9236, 3562.3843, 3665.9099, 3767.5533,
+}
+
+func LevelMultiplier(level int) (float64, error) {
+	if level < 1 || level > len(levelMultiplier) {
+		return 0, ErrInvalidLevel
+	}
+	return levelMultiplier[level-1], nil
+}
+
+// CalcBreakDamage calculates the damage dealt when the attack breaks the enemy's weakness.
+// The enemy's Toughness uses the same units as the attacks' toughness reduction (a Basic ATK usually deals 10).
+// If the attack has no Element, the character's Element will be used.
+// Enemies that are not weak to the attack's Element can't be broken and take no break damage.
+func CalcBreakDamage(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) (float64, string, error) {
+	if err := validateHitBuffs(c, lc, rb, e, a); err != nil {
+		return 0, "", err
+	}
+	c, lc, rb, e, a = applyConditions(c, lc, rb, e, a, HitContext{IsFocused: true})
+	return weightByUptime(c, lc, rb, e, a, UptimeExact, calcBreakDamage)
+}
+
+func calcBreakDamage(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) (float64, string, error) {
+	if a.Element == AnyElement {
+		a.Element = c.Element
+	}
+	if !e.IsWeakTo(a.Element) {
+		return 0, fmt.Sprintf("%s is not weak to %s", e.Name, a.Element), nil
+	}
+	levelMult, err := LevelMultiplier(c.Level)
+	if err != nil {
+		return 0, "", err
+	}
+	elementMult := breakElementMultiplier[a.Element]
+	toughnessMult := 0.5 + e.Toughness/40
+	baseDamage := elementMult * levelMult * toughnessMult
+
+	breakEffectMult := 1 + c.FinalStatValue(lc, rb, BreakEffect, a.DamageTags, a.Element, a.Buffs)/100
+	breakDmgBonusMult := 1 + c.FinalStatValue(lc, rb, BreakDmgBonus, a.DamageTags, a.Element, a.Buffs)/100
+	resMult := CalcResistanceMultiplier(c, lc, rb, e, a)
+	defMult := CalcDefenseMultiplier(c, lc, rb, e, a)
+	vulnMult := CalcVulnerabilityMultiplier(e, a)
+	dmgReductionMult := CalcDmgReductionMultiplier(e, a)
+
+	explanation := fmt.Sprintf(
+		"Break Base Damage: %.2f\n"+
+			"Element Multiplier: %.2f\n"+
+			"Level Multiplier: %.2f\n"+
+			"Max Toughness Multiplier: %.2f\n"+
+			"Break Effect Multiplier: %.2f\n"+
+			"Break Damage Bonus Multiplier: %.2f\n"+
+			"Resistance Multiplier: %.2f\n"+
+			"Defense Multiplier: %.2f\n"+
+			"Vulnerability Multiplier: %.2f\n"+
+			"Damage Reduction Multiplier: %.2f",
+		baseDamage, elementMult, levelMult, toughnessMult, breakEffectMult, breakDmgBonusMult, resMult, defMult, vulnMult, dmgReductionMult)
+	explanation += capsExplanation(c, lc, rb, e, a)
+
+	return baseDamage * breakEffectMult * breakDmgBonusMult * resMult * defMult * vulnMult * dmgReductionMult, explanation, nil
+}
+
+// CalcSuperBreakDamage calculates the Super Break damage dealt by the attack's toughness reduction.
+// The enemy is assumed to be Weakness Broken, Super Break damage can't happen otherwise (see Enemy.Broken).
+// The attack is evaluated as SuperBreak damage, so buffs with the attack's own DamageTags won't apply.
+func CalcSuperBreakDamage(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack, isSplash bool) (float64, string, error) {
+	if err := validateHitBuffs(c, lc, rb, e, a); err != nil {
+		return 0, "", err
+	}
+	return calcSuperBreakHit(c, lc, rb, e, a, HitContext{IsFocused: !isSplash, IsSplash: isSplash}, UptimeExact)
+}
+
+func calcSuperBreakHit(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack, hit HitContext, mode UptimeMode) (float64, string, error) {
+	c, lc, rb, e, a = applyConditions(c, lc, rb, e, a, hit)
+	isSplash := hit.IsSplash
+	return weightByUptime(c, lc, rb, e, a, mode, func(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) (float64, string, error) {
+		return calcSuperBreakDamage(c, lc, rb, e, a, isSplash)
+	})
+}
+
+func calcSuperBreakDamage(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack, isSplash bool) (float64, string, error) {
+	if a.Element == AnyElement {
+		a.Element = c.Element
+	}
+	a.DamageTags = DamageTags{SuperBreak}
+	levelMult, err := LevelMultiplier(c.Level)
+	if err != nil {
+		return 0, "", err
+	}
+	toughnessReduction := a.ToughnessReduction
+	if isSplash {
+		toughnessReduction = a.ToughnessReductionSplash
+	}
+	superBreakMult := c.FinalStatValue(lc, rb, SuperBreakDmg, a.DamageTags, a.Element, a.Buffs) / 100
+	baseDamage := levelMult * toughnessReduction / 10 * superBreakMult
+
+	breakEffectMult := 1 + c.FinalStatValue(lc, rb, BreakEffect, a.DamageTags, a.Element, a.Buffs)/100
+	breakDmgBonusMult := 1 + c.FinalStatValue(lc, rb, BreakDmgBonus, a.DamageTags, a.Element, a.Buffs)/100
+	resMult := CalcResistanceMultiplier(c, lc, rb, e, a)
+	defMult := CalcDefenseMultiplier(c, lc, rb, e, a)
+	vulnMult := CalcVulnerabilityMultiplier(e, a)
+	dmgReductionMult := CalcDmgReductionMultiplier(e, a)
+
+	explanation := fmt.Sprintf(
+		"Super Break Base Damage: %.2f\n"+
+			"Level Multiplier: %.2f\n"+
+			"Toughness Reduction: %.2f\n"+
+			"Super Break Multiplier: %.2f\n"+
+			"Break Effect Multiplier: %.2f\n"+
+			"Break Damage Bonus Multiplier: %.2f\n"+
+			"Resistance Multiplier: %.2f\n"+
+			"Defense Multiplier: %.2f\n"+
+			"Vulnerability Multiplier: %.2f\n"+
+			"Damage Reduction Multiplier: %.2f",
+		baseDamage, levelMult, toughnessReduction, superBreakMult, breakEffectMult, breakDmgBonusMult, resMult, defMult, vulnMult, dmgReductionMult)
+	explanation += capsExplanation(c, lc, rb, e, a)
+
+	return baseDamage * breakEffectMult * breakDmgBonusMult * resMult * defMult * vulnMult * dmgReductionMult, explanation, nil
+}
+
+// hasSuperBreak returns true if the attack reduces toughness and the character has a Super Break multiplier
+func hasSuperBreak(c Character, lc LightCone, rb RelicBuild, a Attack) bool {
+	if a.DamageTags.Has(Break) || a.ToughnessReduction+a.ToughnessReductionSplash <= 0 {
+		return false
+	}
+	element := a.Element
+	if element == AnyElement {
+		element = c.Element
+	}
+	return c.FinalStatValue(lc, rb, SuperBreakDmg, DamageTags{SuperBreak}, element, a.Buffs) > 0
+}
@@ -0,0 +1,118 @@
+package hsrtct_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/j4rv/hsr-tct/pkg/hsrtct"
+)
+
+func TestCalcBreakDamage(t *testing.T) {
+	hook := GetHookCharacter()
+	hook.Buffs = append(hook.Buffs, hsrtct.Buff{Stat: hsrtct.BreakEffect, Value: 100})
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	enemy := GetBasicEnemy()
+	enemy.Toughness = 100
+
+	breakEvent := hsrtct.Attack{
+		Name:       "Fire Break",
+		DamageTags: hsrtct.DamageTags{hsrtct.Break},
+	}
+
+	dmg, _, err := hsrtct.CalcBreakDamage(hook, lc, rb, enemy, breakEvent)
+	assertNilError(t, err)
+	// 2 * 3767.5533 * (0.5 + 100/40) * 2 * 1 * DEF multiplier
+	if int(dmg) != 23498 {
+		t.Fatalf("Expected damage to be 23498, got %v", dmg)
+	}
+}
+
+func TestCalcBreakDamageNotWeak(t *testing.T) {
+	enemy := GetBasicEnemy()
+	enemy.Toughness = 100
+	enemy.Weaknesses = []hsrtct.Element{hsrtct.Ice, hsrtct.Quantum}
+
+	dmg, _, err := hsrtct.CalcBreakDamage(GetHookCharacter(), GetAeonLC(), GetHookRelicBuild(), enemy, hsrtct.Attack{DamageTags: hsrtct.DamageTags{hsrtct.Break}})
+	assertNilError(t, err)
+	if dmg != 0 {
+		t.Fatalf("Expected no break damage, got %v", dmg)
+	}
+}
+
+func TestCalcAvgDmgScenarioWithBreak(t *testing.T) {
+	enemy := GetBasicEnemy()
+	enemy.Toughness = 100
+	attack := hsrtct.Attack{
+		ScalingStat: hsrtct.Atk,
+		Multiplier:  100,
+		Element:     hsrtct.Fire,
+		DamageTags:  hsrtct.DamageTags{hsrtct.Basic},
+	}
+	breakEvent := hsrtct.Attack{DamageTags: hsrtct.DamageTags{hsrtct.Break}}
+
+	scn := hsrtct.Scenario{
+		Character:  GetHookCharacter(),
+		LightCone:  GetAeonLC(),
+		RelicBuild: GetHookRelicBuild(),
+		Enemies:    []hsrtct.Enemy{enemy},
+		Attacks:    map[*hsrtct.Attack]float64{&attack: 2, &breakEvent: 1},
+	}
+
+	scnResult, err := hsrtct.CalcAvgDmgScenario(scn)
+	assertNilError(t, err)
+	if len(scnResult.Explanations) != 2 {
+		t.Fatalf("Expected 2 explanations, got %v", len(scnResult.Explanations))
+	}
+
+	attackDmg, _, err := hsrtct.CalcAvgDamage(scn.Character, scn.LightCone, scn.RelicBuild, enemy, attack, false)
+	assertNilError(t, err)
+	breakDmg, _, err := hsrtct.CalcBreakDamage(scn.Character, scn.LightCone, scn.RelicBuild, enemy, breakEvent)
+	assertNilError(t, err)
+	if breakDmg <= 0 {
+		t.Fatalf("Expected the break event to deal damage, got %v", breakDmg)
+	}
+	if math.Abs(scnResult.TotalDmg-(attackDmg*2+breakDmg)) > 1e-6 {
+		t.Fatalf("Expected total damage to be %v, got %v", attackDmg*2+breakDmg, scnResult.TotalDmg)
+	}
+}
+
+func TestCalcSuperBreakDamage(t *testing.T) {
+	hook := GetHookCharacter()
+	hook.Buffs = append(hook.Buffs,
+		hsrtct.Buff{Stat: hsrtct.BreakEffect, Value: 100},
+		hsrtct.Buff{Stat: hsrtct.SuperBreakDmg, Value: 150},
+	)
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	enemy := GetBasicEnemy()
+	enemy.Broken = true
+
+	skill := hsrtct.Attack{
+		ScalingStat:        hsrtct.Atk,
+		Multiplier:         100,
+		ToughnessReduction: 20,
+		Element:            hsrtct.Fire,
+		DamageTags:         hsrtct.DamageTags{hsrtct.Skill},
+	}
+
+	dmg, _, err := hsrtct.CalcSuperBreakDamage(hook, lc, rb, enemy, skill, false)
+	assertNilError(t, err)
+	// 3767.5533 * 20/10 * 1.5 * 2 * DEF multiplier
+	if int(dmg) != 11749 {
+		t.Fatalf("Expected damage to be 11749, got %v", dmg)
+	}
+
+	scn := hsrtct.Scenario{
+		Character:  hook,
+		LightCone:  lc,
+		RelicBuild: rb,
+		Enemies:    []hsrtct.Enemy{enemy},
+		Attacks:    map[*hsrtct.Attack]float64{&skill: 1},
+	}
+	scnResult, err := hsrtct.CalcAvgDmgScenario(scn)
+	assertNilError(t, err)
+	if len(scnResult.Explanations) != 2 {
+		t.Fatalf("Expected the skill and its Super Break explanations, got %v", len(scnResult.Explanations))
+	}
+}
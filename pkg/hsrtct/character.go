@@ -22,8 +22,55 @@ func (c *Character) AllBuffs(lc LightCone, rb RelicBuild) []Buff {
 	return allBuffs
 }
 
+// ValidateBuffs checks the buffs of the character, its light cone and relic build, and the extra buffs
+func (c *Character) ValidateBuffs(lc LightCone, rb RelicBuild, extraBuffs []Buff) error {
+	allBuffs := c.AllBuffs(lc, rb)
+	allBuffs = append(allBuffs, extraBuffs...)
+	for _, buff := range allBuffs {
+		if err := buff.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // TODO cache!
-func (c *Character) FinalStatValue(lc LightCone, rb RelicBuild, stat Stat, tag DamageTag, element Element, extraBuffs []Buff) float64 {
+func (c *Character) FinalStatValue(lc LightCone, rb RelicBuild, stat Stat, tags DamageTags, element Element, extraBuffs []Buff) float64 {
+	allBuffs := c.AllBuffs(lc, rb)
+	allBuffs = append(allBuffs, extraBuffs...)
+	for _, conversion := range c.ConversionBuffs(lc, rb, tags, element, extraBuffs) {
+		conversion.SourceStat = ""
+		allBuffs = append(allBuffs, conversion)
+	}
+	return c.statValue(lc, stat, tags, element, allBuffs)
+}
+
+// ConversionBuffs returns the conversion buffs that apply to the given tags and element, with their Value resolved.
+// Their source stats are calculated without any conversion buff, so conversions can't loop.
+// Conversions with a Source use the final stats of the Source character instead, see BuffSource.
+func (c *Character) ConversionBuffs(lc LightCone, rb RelicBuild, tags DamageTags, element Element, extraBuffs []Buff) []Buff {
+	var conversions []Buff
+	allBuffs := c.AllBuffs(lc, rb)
+	allBuffs = append(allBuffs, extraBuffs...)
+	for _, buff := range allBuffs {
+		if !buff.IsConversion() || buff.Condition != Always || !tags.Is(buff.DamageTag) || !buff.Element.Is(element) {
+			continue
+		}
+		sourceValue := 0.0
+		if buff.Source != nil {
+			sourceValue = buff.Source.StatValue(buff.SourceStat)
+		} else {
+			sourceValue = c.statValue(lc, buff.SourceStat, tags, element, allBuffs)
+		}
+		buff.Value = buff.ConvertedValue(sourceValue)
+		conversions = append(conversions, buff)
+	}
+	return conversions
+}
+
+// statValue sums the base value of the stat and the buffs, conversion buffs are ignored.
+// Conditional buffs are ignored too, they only apply once applyConditions evaluates them for a hit.
+func (c *Character) statValue(lc LightCone, stat Stat, tags DamageTags, element Element, buffs []Buff) float64 {
 	baseValue := 0.0
 
 	switch stat {
@@ -46,47 +93,71 @@ func (c *Character) FinalStatValue(lc LightCone, rb RelicBuild, stat Stat, tag D
 	}
 
 	value := baseValue
-	allBuffs := c.AllBuffs(lc, rb)
-	allBuffs = append(allBuffs, extraBuffs...)
-	for _, buff := range allBuffs {
-		if !buff.DamageTag.Is(tag) || !buff.Element.Is(element) {
+	for _, buff := range buffs {
+		if buff.IsConversion() || buff.Condition != Always || !tags.Is(buff.DamageTag) || !buff.Element.Is(element) {
 			continue
 		}
 
 		switch stat {
 		case Hp:
 			if buff.Stat == Hp {
-				value += buff.Value
+				value += buff.TotalValue()
 			} else if buff.Stat == HpPct {
-				value += baseValue * buff.Value / 100
+				value += baseValue * buff.TotalValue() / 100
 			}
 		case Atk:
 			if buff.Stat == Atk {
-				value += buff.Value
+				value += buff.TotalValue()
 			} else if buff.Stat == AtkPct {
-				value += baseValue * buff.Value / 100
+				value += baseValue * buff.TotalValue() / 100
 			}
 		case Def:
 			if buff.Stat == Def {
-				value += buff.Value
+				value += buff.TotalValue()
 			} else if buff.Stat == DefPct {
-				value += baseValue * buff.Value / 100
+				value += baseValue * buff.TotalValue() / 100
 			}
 		case Spd:
 			if buff.Stat == Spd {
-				value += buff.Value
+				value += buff.TotalValue()
 			} else if buff.Stat == SpdPct {
-				value += baseValue * buff.Value / 100
+				value += baseValue * buff.TotalValue() / 100
 			}
 		default:
 			if buff.Stat == stat {
-				value += buff.Value
+				value += buff.TotalValue()
 			}
 		}
 	}
 	return value
 }
 
+// BuffSource is a fully built character whose stats are used by the conversion buffs it gives to others.
+// Buffs of the source that have a Source themselves are ignored, so buffs between supports can't loop.
+type BuffSource struct {
+	Character  Character  `json:"character"`
+	LightCone  LightCone  `json:"lightCone"`
+	RelicBuild RelicBuild `json:"relicBuild"`
+}
+
+// StatValue returns the final value of the source character's stat as shown in its sheet, buffs restricted to some attacks are ignored
+func (s BuffSource) StatValue(stat Stat) float64 {
+	withoutSources := func(buffs []Buff) []Buff {
+		result := make([]Buff, 0, len(buffs))
+		for _, buff := range buffs {
+			if buff.Source == nil {
+				result = append(result, buff)
+			}
+		}
+		return result
+	}
+	c, lc, rb := s.Character, s.LightCone, s.RelicBuild
+	c.Buffs = withoutSources(c.Buffs)
+	lc.Buffs = withoutSources(lc.Buffs)
+	rb.SetEffects = withoutSources(rb.SetEffects)
+	return c.FinalStatValue(lc, rb, stat, DamageTags{NonAttack}, AnyElement, nil)
+}
+
 type LightCone struct {
 	ID      uint64  `json:"id"`
 	Name    string  `json:"name"`
@@ -0,0 +1,72 @@
+package hsrtct_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/j4rv/hsr-tct/pkg/hsrtct"
+)
+
+func TestFinalStatValueConversions(t *testing.T) {
+	hook := GetHookCharacter()
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	hook.Buffs = append(hook.Buffs,
+		// ATK increased by 25% of DEF
+		hsrtct.Buff{Stat: hsrtct.Atk, SourceStat: hsrtct.Def, Ratio: 25},
+		// CRIT DMG increases by 2% of HP above 3000, up to 10%
+		hsrtct.Buff{Stat: hsrtct.CritDmg, SourceStat: hsrtct.Hp, Ratio: 2, Threshold: 3000, Cap: 10},
+		// Converting from a converted stat uses its value before conversions
+		hsrtct.Buff{Stat: hsrtct.Def, SourceStat: hsrtct.Atk, Ratio: 10},
+	)
+
+	atk := hook.FinalStatValue(lc, rb, hsrtct.Atk, nil, hsrtct.AnyElement, nil)
+	// 4029.68 ATK + 25% of 748 DEF
+	if int(atk) != 4216 {
+		t.Fatalf("Expected ATK to be 4216, got %v", atk)
+	}
+	def := hook.FinalStatValue(lc, rb, hsrtct.Def, nil, hsrtct.AnyElement, nil)
+	// 748 DEF + 10% of 4029.68 ATK
+	if int(def) != 1150 {
+		t.Fatalf("Expected DEF to be 1150, got %v", def)
+	}
+	critDmg := hook.FinalStatValue(lc, rb, hsrtct.CritDmg, nil, hsrtct.AnyElement, nil)
+	// 133.26 CRIT DMG + min(2% of (3535.24 HP - 3000), 10)
+	if int(critDmg) != 143 {
+		t.Fatalf("Expected CRIT DMG to be 143, got %v", critDmg)
+	}
+}
+
+func TestFinalStatValueBuffSource(t *testing.T) {
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	support := hsrtct.BuffSource{Character: GetHookCharacter(), LightCone: lc, RelicBuild: rb}
+	support.Character.Name = "Support"
+
+	carry := GetHookCharacter()
+	baseCritDmg := carry.FinalStatValue(lc, rb, hsrtct.CritDmg, hsrtct.DamageTags{hsrtct.Skill}, hsrtct.Fire, nil)
+	// CRIT DMG increased by 16% of the support's CRIT DMG
+	carry.Buffs = append(carry.Buffs, hsrtct.Buff{Stat: hsrtct.CritDmg, SourceStat: hsrtct.CritDmg, Ratio: 16, Source: &support})
+
+	critDmg := carry.FinalStatValue(lc, rb, hsrtct.CritDmg, hsrtct.DamageTags{hsrtct.Skill}, hsrtct.Fire, nil)
+	if math.Abs(critDmg-(baseCritDmg+133.26*0.16)) > 1e-9 {
+		t.Fatalf("Expected CRIT DMG to be %v, got %v", baseCritDmg+133.26*0.16, critDmg)
+	}
+
+	// Better support relics, and a buff from the carry to the support, which is ignored
+	support.Character.Buffs = append(support.Character.Buffs,
+		hsrtct.Buff{Stat: hsrtct.CritDmg, Value: 50},
+		hsrtct.Buff{Stat: hsrtct.CritDmg, SourceStat: hsrtct.CritDmg, Ratio: 100, Source: &hsrtct.BuffSource{Character: carry, LightCone: lc, RelicBuild: rb}},
+	)
+	critDmg = carry.FinalStatValue(lc, rb, hsrtct.CritDmg, hsrtct.DamageTags{hsrtct.Skill}, hsrtct.Fire, nil)
+	if math.Abs(critDmg-(baseCritDmg+183.26*0.16)) > 1e-9 {
+		t.Fatalf("Expected CRIT DMG to be %v, got %v", baseCritDmg+183.26*0.16, critDmg)
+	}
+
+	// A buff to the support's skills is not part of its sheet CRIT DMG
+	support.Character.Buffs = append(support.Character.Buffs, hsrtct.Buff{Stat: hsrtct.CritDmg, Value: 100, DamageTag: hsrtct.Skill})
+	critDmg = carry.FinalStatValue(lc, rb, hsrtct.CritDmg, hsrtct.DamageTags{hsrtct.Skill}, hsrtct.Fire, nil)
+	if math.Abs(critDmg-(baseCritDmg+183.26*0.16)) > 1e-9 {
+		t.Fatalf("Expected CRIT DMG to be %v, got %v", baseCritDmg+183.26*0.16, critDmg)
+	}
+}
@@ -0,0 +1,194 @@
+package hsrtct
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrNoRotation = errors.New("scenario has no rotation")
+var ErrNoWaves = errors.New("scenario has no waves")
+var ErrInvalidMaxActions = errors.New("invalid max actions")
+var ErrEmptyWave = errors.New("wave has no enemies")
+
+// ClearAction is an action used while clearing the enemies.
+// Damage only counts the HP the enemies actually lost, the rest of the damage is Overkill.
+type ClearAction struct {
+	Attack   string
+	Damage   float64
+	Overkill float64
+	Killed   []string
+}
+
+// ClearResult is the result of attacking the enemies until they die.
+// Actions is the amount of actions used, Cleared is false if some enemy was still alive after the last one.
+type ClearResult struct {
+	Actions  int
+	Cleared  bool
+	Damage   float64
+	Overkill float64
+	Log      []ClearAction
+}
+
+// SimulateClear uses the scenario's Rotation, repeated in order, until every enemy dies or maxActions are used.
+// Enemies start at HpPct of their MaxHp and every hit deals its average damage.
+// Dead enemies are removed from the fight, so Blast attacks hit the enemies next to the focused one among the living ones.
+// When the focused enemy dies, the attacks focus the next living enemy (or the previous one if there's none).
+// Summons deal their damage per rotation spread evenly over the actions of the rotation, heals and shields are ignored.
+func SimulateClear(s Scenario, maxActions int) (ClearResult, error) {
+	if maxActions <= 0 {
+		return ClearResult{}, fmt.Errorf("%w: %d", ErrInvalidMaxActions, maxActions)
+	}
+	return simulateClear(s, maxActions, 0)
+}
+
+// simulateClear is SimulateClear starting at the given action of the rotation
+func simulateClear(s Scenario, maxActions int, rotationStart int) (ClearResult, error) {
+	if len(s.Rotation) == 0 {
+		return ClearResult{}, ErrNoRotation
+	}
+	hp := make([]float64, len(s.Enemies))
+	for i, enemy := range s.Enemies {
+		if enemy.MaxHp <= 0 {
+			return ClearResult{}, fmt.Errorf("%w: %s has %.2f max HP", ErrInvalidMaxHp, enemy.Name, enemy.MaxHp)
+		}
+		hp[i] = enemy.MaxHp
+		if enemy.HpPct != 0 {
+			hp[i] = enemy.MaxHp * enemy.HpPct / 100
+		}
+	}
+
+	summons := make([]Summon, len(s.Summons))
+	for i, summon := range s.Summons {
+		attacks := make(map[*Attack]float64, len(summon.Attacks))
+		for attack, mult := range summon.Attacks {
+			attacks[attack] = mult / float64(len(s.Rotation))
+		}
+		summon.Attacks = attacks
+		summons[i] = summon
+	}
+
+	result := ClearResult{}
+	focused := s.FocusedEnemy
+	for result.Actions < maxActions {
+		// Living enemies keep their order, alive maps their index to the scenario's Enemies
+		alive := []int{}
+		for i := range s.Enemies {
+			if hp[i] > 0 {
+				alive = append(alive, i)
+			}
+		}
+		if len(alive) == 0 {
+			break
+		}
+		focused = nextAlive(alive, focused)
+
+		attack := s.Rotation[(rotationStart+result.Actions)%len(s.Rotation)]
+		scn := s
+		scn.Enemies = make([]Enemy, len(alive))
+		for j, i := range alive {
+			enemy := s.Enemies[i]
+			enemy.HpPct = hp[i] / enemy.MaxHp * 100
+			scn.Enemies[j] = enemy
+			if i == focused {
+				scn.FocusedEnemy = j
+			}
+		}
+		scn.Attacks = map[*Attack]float64{attack: 1}
+		scn.Summons = summons
+		scn.Heals = nil
+		scn.Shields = nil
+		scn.DeriveUltimates = false
+
+		actionResult, err := CalcAvgDmgScenario(scn)
+		if err != nil {
+			return ClearResult{}, fmt.Errorf("%s: %w", attack.Name, err)
+		}
+
+		action := ClearAction{Attack: attack.Name}
+		for j, i := range alive {
+			dmg := actionResult.EnemyDmg[j]
+			effective := math.Min(dmg, hp[i])
+			hp[i] -= effective
+			action.Damage += effective
+			action.Overkill += dmg - effective
+			if hp[i] <= 0 {
+				action.Killed = append(action.Killed, s.Enemies[i].Name)
+			}
+		}
+		result.Actions++
+		result.Damage += action.Damage
+		result.Overkill += action.Overkill
+		result.Log = append(result.Log, action)
+	}
+
+	result.Cleared = true
+	for _, enemyHp := range hp {
+		if enemyHp > 0 {
+			result.Cleared = false
+		}
+	}
+	return result, nil
+}
+
+// nextAlive returns the focused enemy if it's alive, or the closest living enemy after it (or before it if there's none)
+func nextAlive(alive []int, focused int) int {
+	for _, i := range alive {
+		if i >= focused {
+			return i
+		}
+	}
+	return alive[len(alive)-1]
+}
+
+// Wave is a group of enemies fought together, the next wave starts when every enemy of the previous one dies
+type Wave struct {
+	Enemies      []Enemy
+	FocusedEnemy int
+}
+
+// WavesResult is the result of fighting every wave of a scenario, Waves has the result of each wave that was fought
+type WavesResult struct {
+	Actions  int
+	Cleared  bool
+	Damage   float64
+	Overkill float64
+	Waves    []ClearResult
+}
+
+// SimulateWaves clears the scenario's Waves in order, like SimulateClear, using at most maxActions in total.
+// The rotation spills over into the next wave: it continues from the action after the one that cleared the previous wave.
+// Overkill damage is lost, it doesn't carry over to the next wave.
+// The scenario's Enemies and FocusedEnemy are ignored, every wave must have at least one enemy.
+func SimulateWaves(s Scenario, maxActions int) (WavesResult, error) {
+	if len(s.Waves) == 0 {
+		return WavesResult{}, ErrNoWaves
+	}
+	for i, wave := range s.Waves {
+		if len(wave.Enemies) == 0 {
+			return WavesResult{}, fmt.Errorf("%w: wave %d", ErrEmptyWave, i+1)
+		}
+	}
+	if maxActions <= 0 {
+		return WavesResult{}, fmt.Errorf("%w: %d", ErrInvalidMaxActions, maxActions)
+	}
+	result := WavesResult{Cleared: true}
+	for i, wave := range s.Waves {
+		scn := s
+		scn.Enemies = wave.Enemies
+		scn.FocusedEnemy = wave.FocusedEnemy
+		waveResult, err := simulateClear(scn, maxActions-result.Actions, result.Actions)
+		if err != nil {
+			return WavesResult{}, fmt.Errorf("wave %d: %w", i+1, err)
+		}
+		result.Actions += waveResult.Actions
+		result.Damage += waveResult.Damage
+		result.Overkill += waveResult.Overkill
+		result.Waves = append(result.Waves, waveResult)
+		if !waveResult.Cleared {
+			result.Cleared = false
+			break
+		}
+	}
+	return result, nil
+}
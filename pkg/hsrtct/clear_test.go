@@ -0,0 +1,165 @@
+package hsrtct_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/j4rv/hsr-tct/pkg/hsrtct"
+)
+
+func TestSimulateClear(t *testing.T) {
+	hook := GetHookCharacter()
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	blast := hsrtct.Attack{
+		Name:             "Blast",
+		ScalingStat:      hsrtct.Atk,
+		Multiplier:       100,
+		MultiplierSplash: 100,
+		DamageTags:       hsrtct.DamageTags{hsrtct.Skill},
+		AttackAOE:        hsrtct.Blast,
+	}
+	hitDmg, _, err := hsrtct.CalcAvgDamage(hook, lc, rb, GetBasicEnemy(), blast, false)
+	assertNilError(t, err)
+
+	newEnemy := func(name string, hits float64) hsrtct.Enemy {
+		enemy := GetBasicEnemy()
+		enemy.Name = name
+		enemy.MaxHp = hitDmg * hits
+		return enemy
+	}
+	scn := hsrtct.Scenario{
+		Character:    hook,
+		LightCone:    lc,
+		RelicBuild:   rb,
+		Enemies:      []hsrtct.Enemy{newEnemy("Left", 1.5), newEnemy("Center", 0.5), newEnemy("Right", 2.5)},
+		FocusedEnemy: 1,
+		Rotation:     []*hsrtct.Attack{&blast},
+	}
+
+	// Center dies on the first action, then Right is focused with Left as its neighbour
+	result, err := hsrtct.SimulateClear(scn, 10)
+	assertNilError(t, err)
+	if !result.Cleared || result.Actions != 3 {
+		t.Fatalf("Expected the enemies to be cleared in 3 actions, got %+v", result)
+	}
+	if math.Abs(result.Damage-hitDmg*4.5) > 1e-6 {
+		t.Fatalf("Expected %v effective damage, got %v", hitDmg*4.5, result.Damage)
+	}
+	if math.Abs(result.Overkill-hitDmg*1.5) > 1e-6 {
+		t.Fatalf("Expected %v overkill, got %v", hitDmg*1.5, result.Overkill)
+	}
+	if killed := result.Log[1].Killed; len(killed) != 1 || killed[0] != "Left" {
+		t.Fatalf("Expected Left to die on the second action, got %v", killed)
+	}
+
+	result, err = hsrtct.SimulateClear(scn, 2)
+	assertNilError(t, err)
+	if result.Cleared || result.Actions != 2 {
+		t.Fatalf("Expected the enemies to survive 2 actions, got %+v", result)
+	}
+}
+
+func TestSimulateClearSummons(t *testing.T) {
+	hook := GetHookCharacter()
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	skill := hsrtct.Attack{Name: "Skill", ScalingStat: hsrtct.Atk, Multiplier: 100, DamageTags: hsrtct.DamageTags{hsrtct.Skill}}
+	summonAttack := skill
+	hitDmg, _, err := hsrtct.CalcAvgDamage(hook, lc, rb, GetBasicEnemy(), skill, false)
+	assertNilError(t, err)
+
+	enemy := GetBasicEnemy()
+	enemy.MaxHp = hitDmg * 3.5
+	scn := hsrtct.Scenario{
+		Character:  hook,
+		LightCone:  lc,
+		RelicBuild: rb,
+		Enemies:    []hsrtct.Enemy{enemy},
+		Rotation:   []*hsrtct.Attack{&skill, &skill},
+		Summons:    []hsrtct.Summon{{Name: "Summon", Attacks: map[*hsrtct.Attack]float64{&summonAttack: 2}, InheritBuffs: true}},
+	}
+
+	// The summon attacks twice per rotation, once per action
+	result, err := hsrtct.SimulateClear(scn, 10)
+	assertNilError(t, err)
+	if !result.Cleared || result.Actions != 2 {
+		t.Fatalf("Expected the enemy to be cleared in 2 actions, got %+v", result)
+	}
+	if math.Abs(result.Log[0].Damage-hitDmg*2) > 1e-6 {
+		t.Fatalf("Expected the first action to deal %v damage, got %v", hitDmg*2, result.Log[0].Damage)
+	}
+}
+
+func TestSimulateClearErrors(t *testing.T) {
+	scn := hsrtct.Scenario{Enemies: []hsrtct.Enemy{GetBasicEnemy()}}
+	_, err := hsrtct.SimulateClear(scn, 10)
+	if !errors.Is(err, hsrtct.ErrNoRotation) {
+		t.Fatalf("Expected ErrNoRotation, got %v", err)
+	}
+	scn.Rotation = []*hsrtct.Attack{{Name: "Basic"}}
+	_, err = hsrtct.SimulateClear(scn, 10)
+	if !errors.Is(err, hsrtct.ErrInvalidMaxHp) {
+		t.Fatalf("Expected ErrInvalidMaxHp, got %v", err)
+	}
+	_, err = hsrtct.SimulateClear(scn, 0)
+	if !errors.Is(err, hsrtct.ErrInvalidMaxActions) {
+		t.Fatalf("Expected ErrInvalidMaxActions, got %v", err)
+	}
+}
+
+func TestSimulateWaves(t *testing.T) {
+	hook := GetHookCharacter()
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	big := hsrtct.Attack{Name: "Big", ScalingStat: hsrtct.Atk, Multiplier: 200, DamageTags: hsrtct.DamageTags{hsrtct.Skill}}
+	small := hsrtct.Attack{Name: "Small", ScalingStat: hsrtct.Atk, Multiplier: 100, DamageTags: hsrtct.DamageTags{hsrtct.Skill}}
+	smallDmg, _, err := hsrtct.CalcAvgDamage(hook, lc, rb, GetBasicEnemy(), small, false)
+	assertNilError(t, err)
+
+	newWave := func(hits float64) hsrtct.Wave {
+		enemy := GetBasicEnemy()
+		enemy.MaxHp = smallDmg * hits
+		return hsrtct.Wave{Enemies: []hsrtct.Enemy{enemy}}
+	}
+	scn := hsrtct.Scenario{
+		Character:  hook,
+		LightCone:  lc,
+		RelicBuild: rb,
+		Rotation:   []*hsrtct.Attack{&big, &small},
+		Waves:      []hsrtct.Wave{newWave(1.5), newWave(2.5)},
+	}
+
+	// Big clears the first wave, so the second wave starts with Small
+	result, err := hsrtct.SimulateWaves(scn, 10)
+	assertNilError(t, err)
+	if !result.Cleared || result.Actions != 3 || len(result.Waves) != 2 {
+		t.Fatalf("Expected both waves to be cleared in 3 actions, got %+v", result)
+	}
+	if result.Waves[0].Actions != 1 || result.Waves[1].Actions != 2 {
+		t.Fatalf("Expected 1 and 2 actions per wave, got %d and %d", result.Waves[0].Actions, result.Waves[1].Actions)
+	}
+	if result.Waves[1].Log[0].Attack != "Small" {
+		t.Fatalf("Expected the rotation to spill over into the second wave, got %v", result.Waves[1].Log[0].Attack)
+	}
+	if math.Abs(result.Damage-smallDmg*4) > 1e-6 || math.Abs(result.Overkill-smallDmg) > 1e-6 {
+		t.Fatalf("Expected %v damage and %v overkill, got %v and %v", smallDmg*4, smallDmg, result.Damage, result.Overkill)
+	}
+
+	result, err = hsrtct.SimulateWaves(scn, 2)
+	assertNilError(t, err)
+	if result.Cleared || result.Actions != 2 || result.Waves[1].Cleared {
+		t.Fatalf("Expected the second wave to survive, got %+v", result)
+	}
+
+	_, err = hsrtct.SimulateWaves(hsrtct.Scenario{Rotation: scn.Rotation}, 10)
+	if !errors.Is(err, hsrtct.ErrNoWaves) {
+		t.Fatalf("Expected ErrNoWaves, got %v", err)
+	}
+	scn.Waves = append(scn.Waves, hsrtct.Wave{})
+	_, err = hsrtct.SimulateWaves(scn, 10)
+	if !errors.Is(err, hsrtct.ErrEmptyWave) {
+		t.Fatalf("Expected ErrEmptyWave, got %v", err)
+	}
+}
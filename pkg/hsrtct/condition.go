@@ -0,0 +1,72 @@
+package hsrtct
+
+// Condition is a requirement for a buff to apply to a hit, evaluated against the hit enemy and the HitContext
+type Condition string
+
+const (
+	Always Condition = ""
+	// The enemy is Weakness Broken
+	TargetBroken Condition = "TargetBroken"
+	// The enemy's HP is below ConditionValue% of its max HP
+	TargetHpBelow Condition = "TargetHpBelow"
+	// The enemy has at least ConditionValue debuffs
+	TargetDebuffed Condition = "TargetDebuffed"
+	// The enemy is weak to the character's element, enemies without Weaknesses are weak to every element
+	TargetWeak Condition = "TargetWeak"
+	// The enemy is the focused one
+	FocusedTarget Condition = "FocusedTarget"
+	// The enemy is hit by a splash (e.g. the sides of a Blast attack)
+	SplashTarget Condition = "SplashTarget"
+)
+
+// HitContext is the situation in which a hit lands on an enemy
+type HitContext struct {
+	IsFocused bool
+	IsSplash  bool
+}
+
+// IsMet returns true if the condition is met by a hit of a character on the enemy
+func (cond Condition) IsMet(b Buff, c Character, e Enemy, hit HitContext) bool {
+	switch cond {
+	case TargetBroken:
+		return e.Broken
+	case TargetHpBelow:
+		hpPct := e.HpPct
+		if hpPct == 0 {
+			hpPct = 100
+		}
+		return hpPct < b.ConditionValue
+	case TargetDebuffed:
+		return float64(e.Debuffs) >= b.ConditionValue
+	case TargetWeak:
+		return e.IsWeakTo(c.Element)
+	case FocusedTarget:
+		return hit.IsFocused
+	case SplashTarget:
+		return hit.IsSplash
+	}
+	return true
+}
+
+// applyConditions returns copies of the buff holders without the conditional buffs that don't apply to the hit.
+// The conditional buffs that apply become unconditional: the character's stats (see Character.FinalStatValue)
+// leave out every conditional buff, so heals, shields, buff sources and timelines never use them.
+func applyConditions(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack, hit HitContext) (Character, LightCone, RelicBuild, Enemy, Attack) {
+	filter := func(buffs []Buff) []Buff {
+		result := make([]Buff, 0, len(buffs))
+		for _, buff := range buffs {
+			if buff.Condition.IsMet(buff, c, e, hit) {
+				buff.Condition = Always
+				result = append(result, buff)
+			}
+		}
+		return result
+	}
+	cc, lcc, rbc, ec, ac := c, lc, rb, e, a
+	cc.Buffs = filter(c.Buffs)
+	lcc.Buffs = filter(lc.Buffs)
+	rbc.SetEffects = filter(rb.SetEffects)
+	ec.Buffs = filter(e.Buffs)
+	ac.Buffs = filter(a.Buffs)
+	return cc, lcc, rbc, ec, ac
+}
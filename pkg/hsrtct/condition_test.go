@@ -0,0 +1,128 @@
+package hsrtct_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/j4rv/hsr-tct/pkg/hsrtct"
+)
+
+func TestConditionalBuffsBlast(t *testing.T) {
+	hook := GetHookCharacter()
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+
+	blast := hsrtct.Attack{
+		ScalingStat:      hsrtct.Atk,
+		Multiplier:       100,
+		MultiplierSplash: 100,
+		Element:          hsrtct.Fire,
+		DamageTags:       hsrtct.DamageTags{hsrtct.Skill},
+		AttackAOE:        hsrtct.Blast,
+		Buffs: []hsrtct.Buff{
+			{Stat: hsrtct.DmgBonus, Value: 50, Condition: hsrtct.FocusedTarget},
+			{Stat: hsrtct.DmgBonus, Value: 20, Condition: hsrtct.SplashTarget},
+		},
+	}
+
+	focusedAttack := blast
+	focusedAttack.Buffs = []hsrtct.Buff{{Stat: hsrtct.DmgBonus, Value: 50}}
+	focusedDmg, _, err := hsrtct.CalcAvgDamage(hook, lc, rb, GetBasicEnemy(), focusedAttack, false)
+	assertNilError(t, err)
+	splashAttack := blast
+	splashAttack.Buffs = []hsrtct.Buff{{Stat: hsrtct.DmgBonus, Value: 20}}
+	splashDmg, _, err := hsrtct.CalcAvgDamage(hook, lc, rb, GetBasicEnemy(), splashAttack, true)
+	assertNilError(t, err)
+
+	scn := hsrtct.Scenario{
+		Character:    hook,
+		LightCone:    lc,
+		RelicBuild:   rb,
+		Enemies:      []hsrtct.Enemy{GetBasicEnemy(), GetBasicEnemy(), GetBasicEnemy()},
+		FocusedEnemy: 1,
+		Attacks:      map[*hsrtct.Attack]float64{&blast: 1},
+	}
+	result, err := hsrtct.CalcAvgDmgScenario(scn)
+	assertNilError(t, err)
+
+	expected := focusedDmg + splashDmg*2
+	if int(result.TotalDmg) != int(expected) {
+		t.Fatalf("Expected damage to be %v, got %v", expected, result.TotalDmg)
+	}
+}
+
+func TestConditionalBuffsEnemyState(t *testing.T) {
+	hook := GetHookCharacter()
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	attack := hsrtct.Attack{
+		ScalingStat: hsrtct.Atk,
+		Multiplier:  100,
+		DamageTags:  hsrtct.DamageTags{hsrtct.Basic},
+		Buffs: []hsrtct.Buff{
+			{Stat: hsrtct.DmgBonus, Value: 30, Condition: hsrtct.TargetHpBelow, ConditionValue: 50},
+			{Stat: hsrtct.CritDmg, Value: 20, Condition: hsrtct.TargetDebuffed, ConditionValue: 2},
+			{Stat: hsrtct.DefIgnore, Value: 10, Condition: hsrtct.TargetBroken},
+			{Stat: hsrtct.AtkPct, Value: 10, Condition: hsrtct.TargetWeak},
+		},
+	}
+	unconditional := attack
+	unconditional.Buffs = nil
+
+	fullHpEnemy := GetBasicEnemy()
+	fullHpEnemy.Debuffs = 1
+	fullHpEnemy.Weaknesses = []hsrtct.Element{hsrtct.Ice}
+	expected, _, err := hsrtct.CalcAvgDamage(hook, lc, rb, fullHpEnemy, unconditional, false)
+	assertNilError(t, err)
+	dmg, _, err := hsrtct.CalcAvgDamage(hook, lc, rb, fullHpEnemy, attack, false)
+	assertNilError(t, err)
+	if int(dmg) != int(expected) {
+		t.Fatalf("Expected no conditional buff to apply, expected %v, got %v", expected, dmg)
+	}
+
+	lowHpEnemy := GetBasicEnemy()
+	lowHpEnemy.HpPct = 40
+	lowHpEnemy.Debuffs = 2
+	lowHpEnemy.Broken = true
+	lowHpEnemy.Weaknesses = []hsrtct.Element{hook.Element}
+	unconditional.Buffs = []hsrtct.Buff{
+		{Stat: hsrtct.DmgBonus, Value: 30},
+		{Stat: hsrtct.CritDmg, Value: 20},
+		{Stat: hsrtct.DefIgnore, Value: 10},
+		{Stat: hsrtct.AtkPct, Value: 10},
+	}
+	expected, _, err = hsrtct.CalcAvgDamage(hook, lc, rb, lowHpEnemy, unconditional, false)
+	assertNilError(t, err)
+	dmg, _, err = hsrtct.CalcAvgDamage(hook, lc, rb, lowHpEnemy, attack, false)
+	assertNilError(t, err)
+	if int(dmg) != int(expected) {
+		t.Fatalf("Expected every conditional buff to apply, expected %v, got %v", expected, dmg)
+	}
+
+	// Enemies without weaknesses are weak to every element
+	lowHpEnemy.Weaknesses = nil
+	dmg, _, err = hsrtct.CalcAvgDamage(hook, lc, rb, lowHpEnemy, attack, false)
+	assertNilError(t, err)
+	if int(dmg) != int(expected) {
+		t.Fatalf("Expected TargetWeak to apply to enemies without weaknesses, expected %v, got %v", expected, dmg)
+	}
+}
+
+func TestConditionalBuffsOutsideHits(t *testing.T) {
+	luocha := GetLuochaCharacter()
+	heal := hsrtct.Heal{ScalingStat: hsrtct.Atk, Multiplier: 60, Flat: 800}
+	expected, _, err := hsrtct.CalcHeal(luocha, hsrtct.LightCone{}, hsrtct.RelicBuild{}, heal, nil)
+	assertNilError(t, err)
+
+	luocha.Buffs = append(luocha.Buffs, hsrtct.Buff{Stat: hsrtct.AtkPct, Value: 50, Condition: hsrtct.TargetHpBelow, ConditionValue: 50})
+	healing, _, err := hsrtct.CalcHeal(luocha, hsrtct.LightCone{}, hsrtct.RelicBuild{}, heal, nil)
+	assertNilError(t, err)
+	if healing != expected {
+		t.Fatalf("Expected conditional buffs not to affect heals, expected %v, got %v", expected, healing)
+	}
+
+	atk := luocha.FinalStatValue(hsrtct.LightCone{}, hsrtct.RelicBuild{}, hsrtct.Atk, hsrtct.DamageTags{hsrtct.NonAttack}, hsrtct.AnyElement, nil)
+	if math.Abs(atk-756*1.28) > 1e-9 {
+		t.Fatalf("Expected conditional buffs to be left out of the character's stats, got %v ATK", atk)
+	}
+}
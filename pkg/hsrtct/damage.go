@@ -4,27 +4,109 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"strings"
 )
 
 var ErrInvalidScalingStat = errors.New("invalid scaling stat")
+var ErrInvalidHits = errors.New("invalid amount of hits")
 
 type DamageTag string
 
 const (
-	AnyAttack DamageTag = ""
-	Basic     DamageTag = "Basic"
-	Skill     DamageTag = "Skill"
-	Ultimate  DamageTag = "Ultimate"
-	FollowUp  DamageTag = "FollowUp"
-	Dot       DamageTag = "Dot"
+	AnyAttack  DamageTag = ""
+	Basic      DamageTag = "Basic"
+	Skill      DamageTag = "Skill"
+	Ultimate   DamageTag = "Ultimate"
+	FollowUp   DamageTag = "FollowUp"
+	Dot        DamageTag = "Dot"
+	Burn       DamageTag = "Burn"
+	Shock      DamageTag = "Shock"
+	Bleed      DamageTag = "Bleed"
+	WindShear  DamageTag = "WindShear"
+	Break      DamageTag = "Break"
+	SuperBreak DamageTag = "SuperBreak"
+	// NonAttack is the tag of stats that are not calculated for an attack (e.g. heals or shields),
+	// only buffs without a DamageTag apply to it
+	NonAttack DamageTag = "NonAttack"
 )
 
+// Is returns true if the attack's tag d matches the buff's tag. Dot buffs apply to every DoT kind
+// (Burn, Shock, Bleed, WindShear), but buffs of a DoT kind don't apply to generic Dot attacks.
 func (d DamageTag) Is(tag DamageTag) bool {
+	if tag == Dot && d.IsDot() {
+		return true
+	}
 	return d == tag || d == AnyAttack || tag == AnyAttack
 }
 
+func (d DamageTag) IsDot() bool {
+	switch d {
+	case Dot, Burn, Shock, Bleed, WindShear:
+		return true
+	}
+	return false
+}
+
 func AllDamageTags() []DamageTag {
-	return []DamageTag{Basic, Skill, Ultimate, FollowUp, Dot}
+	return []DamageTag{Basic, Skill, Ultimate, FollowUp, Dot, Burn, Shock, Bleed, WindShear, Break, SuperBreak}
+}
+
+func AllDotTags() []DamageTag {
+	return []DamageTag{Dot, Burn, Shock, Bleed, WindShear}
+}
+
+// DamageTags is the set of tags of an attack, a hit can count as several damage types at once.
+// An empty set matches any tag, like AnyAttack.
+type DamageTags []DamageTag
+
+// ParseDamageTags parses a comma separated list of tags, like "FollowUp,Ultimate"
+func ParseDamageTags(s string) DamageTags {
+	var tags DamageTags
+	for _, tag := range strings.Split(s, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, DamageTag(tag))
+		}
+	}
+	return tags
+}
+
+// Is returns true if any of the tags matches the given one
+func (ts DamageTags) Is(tag DamageTag) bool {
+	if len(ts) == 0 {
+		return true
+	}
+	for _, t := range ts {
+		if t.Is(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Has returns true if the given tag is in the set, without wildcard matching
+func (ts DamageTags) Has(tag DamageTag) bool {
+	for _, t := range ts {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (ts DamageTags) IsDot() bool {
+	_, ok := ts.DotTag()
+	return ok
+}
+
+// DotTag returns the first DoT kind in the set
+func (ts DamageTags) DotTag() (DamageTag, bool) {
+	for _, t := range ts {
+		if t.IsDot() {
+			return t, true
+		}
+	}
+	return AnyAttack, false
 }
 
 type AttackAOE string
@@ -34,6 +116,7 @@ const (
 	Blast             AttackAOE = "Blast"
 	All               AttackAOE = "All"
 	EvenlyDistributed AttackAOE = "EvenlyDistributed"
+	Bounce            AttackAOE = "Bounce"
 )
 
 func ParseAttackAOE(s string) (AttackAOE, error) {
@@ -47,6 +130,8 @@ func ParseAttackAOE(s string) (AttackAOE, error) {
 		return All, nil
 	case "EvenlyDistributed":
 		return EvenlyDistributed, nil
+	case "Bounce":
+		return Bounce, nil
 	}
 	return Single, fmt.Errorf("invalid attack AOE: %s", s)
 }
@@ -56,16 +141,56 @@ func ParseAttackAOE(s string) (AttackAOE, error) {
 // If AOE is Blast, will use Multiplier for the focused enemy and MultiplierSplash for its neighbors.
 // If AOE is All, will use Multiplier for all enemies.
 // If AOE is EvenlyDistributed, will use a percentage of Multiplier on each enemy, evenly distributed.
+// If AOE is Bounce, will deal Hits hits of Multiplier, each one on a random enemy. If FirstHitFocused is true,
+// the first hit will always land on the focused enemy.
+// If DamageTags has Break, the attack is a weakness break event: ScalingStat and multipliers are ignored.
+// ToughnessReduction and ToughnessReductionSplash follow the same rules as the multipliers, and are used for Super Break.
+// ScalingStat can be EnemyMaxHp (e.g. for Bleed). If CapMultiplier is set, the base damage
+// can't exceed CapMultiplier% of the character's CapScalingStat.
+// Scalings are extra base damage components added to the ScalingStat one, and FlatDamage is added to every hit.
+// DotStacks is the number of stacks of a DoT (e.g. Wind Shear), each stack deals the full damage. Zero means one stack.
+// EnergyCost is the energy needed to use the attack (ultimates), EnergyGain the base energy gained by using it.
+// SpCost and SpGain are the skill points spent and generated by using the attack.
 type Attack struct {
-	ID               uint64
-	Name             string
-	ScalingStat      Stat
+	ID                       uint64
+	Name                     string
+	ScalingStat              Stat
+	Multiplier               float64
+	MultiplierSplash         float64
+	Scalings                 []Scaling
+	FlatDamage               float64
+	CapScalingStat           Stat
+	CapMultiplier            float64
+	DotStacks                int
+	ToughnessReduction       float64
+	ToughnessReductionSplash float64
+	Element                  Element
+	DamageTags               DamageTags
+	AttackAOE                AttackAOE
+	Hits                     int
+	FirstHitFocused          bool
+	EnergyCost               float64
+	EnergyGain               float64
+	SpCost                   int
+	SpGain                   int
+	Buffs                    []Buff
+}
+
+// Scaling is a component of an attack's base damage: Multiplier% of Stat
+type Scaling struct {
+	Stat             Stat
 	Multiplier       float64
 	MultiplierSplash float64
-	Element          Element
-	DamageTag        DamageTag
-	AttackAOE        AttackAOE
-	Buffs            []Buff
+}
+
+// AllScalings returns the base damage components of the attack, ScalingStat first.
+// Attacks with extra Scalings or FlatDamage may leave ScalingStat empty.
+func (a Attack) AllScalings() []Scaling {
+	scalings := []Scaling{}
+	if a.ScalingStat != "" || len(a.Scalings) == 0 && a.FlatDamage == 0 {
+		scalings = append(scalings, Scaling{Stat: a.ScalingStat, Multiplier: a.Multiplier, MultiplierSplash: a.MultiplierSplash})
+	}
+	return append(scalings, a.Scalings...)
 }
 
 type Scenario struct {
@@ -78,70 +203,340 @@ type Scenario struct {
 	Enemies      []Enemy
 	FocusedEnemy int
 	Attacks      map[*Attack]float64
+	// Stacks of the named buffs while calculating each attack, used to model buffs that ramp up
+	StackOverrides map[*Attack]map[string]int
+	UptimeMode     UptimeMode
+	Heals          map[*Heal]float64
+	Shields        map[*Shield]float64
+	// Buffs on the healed ally, only IncomingHealingBoost is used
+	HealTargetBuffs []Buff
+	// Energy gained per rotation outside of the scenario attacks
+	Energy EnergyModel
+	// If true, the amount of the attack with an EnergyCost is derived from the energy gained per rotation
+	DeriveUltimates bool
+	// Skill points generated (or spent if negative) per rotation by the rest of the team
+	TeamSpBalance float64
+	// Summons of the Character, their damage is included in the scenario results
+	Summons []Summon
+	// Order of the Character's actions, used by SimulateClear and SimulateWaves
+	Rotation []*Attack
+	// Waves of enemies fought in order, used by SimulateWaves instead of Enemies
+	Waves []Wave
+}
+
+// withStackOverrides returns a copy of the scenario and the attack, with the attack's stack overrides applied
+func (s Scenario) withStackOverrides(a *Attack) (Scenario, Attack) {
+	attack := *a
+	stacks := s.StackOverrides[a]
+	if len(stacks) == 0 {
+		return s, attack
+	}
+
+	s.Character.Buffs = withStacks(s.Character.Buffs, stacks)
+	s.LightCone.Buffs = withStacks(s.LightCone.Buffs, stacks)
+	s.RelicBuild.SetEffects = withStacks(s.RelicBuild.SetEffects, stacks)
+	attack.Buffs = withStacks(attack.Buffs, stacks)
+	enemies := make([]Enemy, len(s.Enemies))
+	for i, enemy := range s.Enemies {
+		enemy.Buffs = withStacks(enemy.Buffs, stacks)
+		enemies[i] = enemy
+	}
+	s.Enemies = enemies
+	return s, attack
+}
+
+// validateBuffs checks every buff that will be used to calculate the attack
+func (s Scenario) validateBuffs(a Attack) error {
+	allBuffs := s.Character.AllBuffs(s.LightCone, s.RelicBuild)
+	allBuffs = append(allBuffs, a.Buffs...)
+	for _, enemy := range s.Enemies {
+		allBuffs = append(allBuffs, enemy.Buffs...)
+	}
+	for _, buff := range allBuffs {
+		if err := buff.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 type ScenarioResult struct {
-	TotalDmg     float64
-	Explanations []string
+	TotalDmg float64
+	// Total damage if no hit crits, and if every hit that can crit does
+	MinDmg float64
+	MaxDmg float64
+	// Variance of TotalDmg, assuming every hit crits independently
+	DmgVariance float64
+	TotalHeal   float64
+	TotalShield float64
+	// Damage dealt by each kind of DoT, also included in TotalDmg
+	DotDmg map[DamageTag]float64
+	// Damage dealt by each summon, by name, also included in TotalDmg
+	SummonDmg map[string]float64
+	// Damage dealt to each enemy, indexed like the scenario's Enemies
+	EnemyDmg []float64
+	// Shield strength of a single use of each shield
+	ShieldPerAction map[*Shield]float64
+	Explanations    []string
+	// Every damage roll of the scenario, used by SimulateScenario
+	hits []scenarioHit
+}
+
+// scenarioHit is a hit that is rolled independently rolls times per rotation, each roll dealing scale times its damage.
+// Bounce attacks are a single scenarioHit with bounces hits per roll, each one landing on a random enemy of targets
+// (the first one on the focused enemy if firstHitFocused is true).
+type scenarioHit struct {
+	dist            DamageDistribution
+	rolls           float64
+	scale           float64
+	bounces         int
+	targets         []DamageDistribution
+	focused         int
+	firstHitFocused bool
 }
 
 func CalcAvgDmgScenario(s Scenario) (ScenarioResult, error) {
 	totalDmg := 0.0
+	minDmg := 0.0
+	maxDmg := 0.0
+	dmgVariance := 0.0
+	dotDmg := make(map[DamageTag]float64)
+	enemyDmg := make([]float64, len(s.Enemies))
 	explanations := []string{}
-	for attack, mult := range s.Attacks {
+	hits := []scenarioHit{}
+	if s.DeriveUltimates {
+		var exp string
+		var err error
+		if s, exp, err = s.WithDerivedUltimates(); err != nil {
+			return ScenarioResult{}, err
+		}
+		if exp != "" {
+			explanations = append(explanations, exp)
+		}
+	}
+	for attackRef, mult := range s.Attacks {
+		scn, attack := s.withStackOverrides(attackRef)
+		if err := scn.validateBuffs(attack); err != nil {
+			return ScenarioResult{}, err
+		}
+		// Bounce hits are rolled together, so every use of the attack lands exactly attack.Hits hits
+		var bounceTargets []DamageDistribution
+		if attack.AttackAOE == Bounce {
+			bounceTargets = make([]DamageDistribution, len(scn.Enemies))
+		}
+
+		// weight is the expected amount of times the hit lands on the enemy per use of the attack
+		addHit := func(enemyIndex int, label string, isSplash bool, weight float64) error {
+			enemy := scn.Enemies[enemyIndex]
+			hit := HitContext{IsFocused: enemyIndex == scn.FocusedEnemy, IsSplash: isSplash}
+			dist, exp, err := calcHitDistribution(scn.Character, scn.LightCone, scn.RelicBuild, enemy, attack, hit, s.UptimeMode)
+			if err != nil {
+				return err
+			}
+			dmg := dist.Expected * weight
+			totalDmg += dmg * mult
+			enemyDmg[enemyIndex] += dmg * mult
+			minDmg += dist.NonCrit * weight * mult
+			maxDmg += dist.Crit * weight * mult
+			// Evenly distributed damage splits a single crit roll, other weights are amounts of independent hits
+			if attack.AttackAOE == Bounce {
+				dmgVariance += dist.Variance * weight * mult
+				bounceTargets[enemyIndex] = dist
+			} else if attack.AttackAOE == EvenlyDistributed {
+				dmgVariance += dist.Variance * weight * weight * mult
+				hits = append(hits, scenarioHit{dist: dist, rolls: mult, scale: weight})
+			} else {
+				dmgVariance += dist.Variance * weight * mult
+				hits = append(hits, scenarioHit{dist: dist, rolls: mult * weight, scale: 1})
+			}
+			if dotTag, ok := attack.DamageTags.DotTag(); ok {
+				dotDmg[dotTag] += dmg * mult
+			}
+			explanations = append(explanations, fmt.Sprintf("%s on %s%s:\nDamage: %f\n\n%s", attack.Name, enemy.Name, label, dmg, exp))
+
+			hitC, hitLC, hitRB, _, hitAttack := applyConditions(scn.Character, scn.LightCone, scn.RelicBuild, enemy, attack, hit)
+			if !enemy.Broken || !hasSuperBreak(hitC, hitLC, hitRB, hitAttack) {
+				return nil
+			}
+			superBreakDmg, exp, err := calcSuperBreakHit(scn.Character, scn.LightCone, scn.RelicBuild, enemy, attack, hit, s.UptimeMode)
+			if err != nil {
+				return err
+			}
+			superBreakDmg *= weight
+			totalDmg += superBreakDmg * mult
+			enemyDmg[enemyIndex] += superBreakDmg * mult
+			minDmg += superBreakDmg * mult
+			maxDmg += superBreakDmg * mult
+			superBreakDist := DamageDistribution{NonCrit: superBreakDmg, Crit: superBreakDmg, Expected: superBreakDmg}
+			hits = append(hits, scenarioHit{dist: superBreakDist, rolls: mult, scale: 1})
+			explanations = append(explanations, fmt.Sprintf("%s on %s%s (Super Break):\nDamage: %f\n\n%s", attack.Name, enemy.Name, label, superBreakDmg, exp))
+			return nil
+		}
+
 		switch attack.AttackAOE {
 
 		case Single:
-			dmg, exp, err := CalcAvgDamage(s.Character, s.LightCone, s.RelicBuild, s.Enemies[s.FocusedEnemy], *attack, false)
-			if err != nil {
+			if err := addHit(scn.FocusedEnemy, "", false, 1); err != nil {
 				return ScenarioResult{}, err
 			}
-			totalDmg += dmg * mult
-			explanations = append(explanations, fmt.Sprintf("%s on %s:\nDamage: %f\n\n%s", attack.Name, s.Enemies[s.FocusedEnemy].Name, dmg, exp))
 
 		case Blast:
-			avgDmg, exp, err := CalcAvgDamage(s.Character, s.LightCone, s.RelicBuild, s.Enemies[s.FocusedEnemy], *attack, false)
-			if err != nil {
+			if err := addHit(scn.FocusedEnemy, " (center)", false, 1); err != nil {
 				return ScenarioResult{}, err
 			}
-			totalDmg += avgDmg * mult
-			explanations = append(explanations, fmt.Sprintf("%s on %s (center):\nDamage: %f\n\n%s", attack.Name, s.Enemies[s.FocusedEnemy].Name, avgDmg, exp))
-			if s.FocusedEnemy-1 >= 0 {
-				splashDmg, exp, err := CalcAvgDamage(s.Character, s.LightCone, s.RelicBuild, s.Enemies[s.FocusedEnemy-1], *attack, true)
-				if err != nil {
+			if scn.FocusedEnemy-1 >= 0 {
+				if err := addHit(scn.FocusedEnemy-1, " (left)", true, 1); err != nil {
 					return ScenarioResult{}, err
 				}
-				explanations = append(explanations, fmt.Sprintf("%s on %s (left):\nDamage: %f\n\n%s", attack.Name, s.Enemies[s.FocusedEnemy-1].Name, splashDmg, exp))
-				totalDmg += splashDmg * mult
 			}
-			if s.FocusedEnemy+1 < len(s.Enemies) {
-				splashDmg, exp, err := CalcAvgDamage(s.Character, s.LightCone, s.RelicBuild, s.Enemies[s.FocusedEnemy+1], *attack, true)
-				if err != nil {
+			if scn.FocusedEnemy+1 < len(scn.Enemies) {
+				if err := addHit(scn.FocusedEnemy+1, " (right)", true, 1); err != nil {
 					return ScenarioResult{}, err
 				}
-				explanations = append(explanations, fmt.Sprintf("%s on %s (right):\nDamage: %f\n\n%s", attack.Name, s.Enemies[s.FocusedEnemy+1].Name, splashDmg, exp))
-				totalDmg += splashDmg * mult
 			}
 
 		case All, EvenlyDistributed:
-			for _, enemy := range s.Enemies {
-				dmg, exp, err := CalcAvgDamage(s.Character, s.LightCone, s.RelicBuild, enemy, *attack, false)
-				if err != nil {
+			weight := 1.0
+			if attack.AttackAOE == EvenlyDistributed {
+				weight /= float64(len(scn.Enemies))
+			}
+			for i := range scn.Enemies {
+				if err := addHit(i, "", false, weight); err != nil {
 					return ScenarioResult{}, err
 				}
-				if attack.AttackAOE == EvenlyDistributed {
-					dmg /= float64(len(s.Enemies))
+			}
+
+		case Bounce:
+			// Bounce attacks need at least one hit, including the focused one if FirstHitFocused is true
+			if attack.Hits <= 0 {
+				return ScenarioResult{}, fmt.Errorf("%w: %s is a Bounce attack with %d hits", ErrInvalidHits, attack.Name, attack.Hits)
+			}
+			for i := range scn.Enemies {
+				expectedHits := ExpectedBounceHits(attack, len(scn.Enemies), i == scn.FocusedEnemy)
+				label := fmt.Sprintf(" (%.2f hits)", expectedHits)
+				if err := addHit(i, label, false, expectedHits); err != nil {
+					return ScenarioResult{}, err
 				}
-				explanations = append(explanations, fmt.Sprintf("%s on %s:\nDamage: %f\n\n%s", attack.Name, enemy.Name, dmg, exp))
-				totalDmg += dmg * mult
 			}
+			if len(bounceTargets) == 0 {
+				break
+			}
+			hits = append(hits, scenarioHit{
+				dist:            bounceTargets[scn.FocusedEnemy],
+				rolls:           mult,
+				scale:           1,
+				bounces:         attack.Hits,
+				targets:         bounceTargets,
+				focused:         scn.FocusedEnemy,
+				firstHitFocused: attack.FirstHitFocused,
+			})
 		}
 	}
-	return ScenarioResult{totalDmg, explanations}, nil
+
+	summonDmg := make(map[string]float64)
+	for _, summon := range s.Summons {
+		summonScn, err := s.summonScenario(summon)
+		if err != nil {
+			return ScenarioResult{}, fmt.Errorf("%s: %w", summon.Name, err)
+		}
+		summonResult, err := CalcAvgDmgScenario(summonScn)
+		if err != nil {
+			return ScenarioResult{}, fmt.Errorf("%s: %w", summon.Name, err)
+		}
+		summonDmg[summon.Name] += summonResult.TotalDmg
+		for i, dmg := range summonResult.EnemyDmg {
+			enemyDmg[i] += dmg
+		}
+		totalDmg += summonResult.TotalDmg
+		minDmg += summonResult.MinDmg
+		maxDmg += summonResult.MaxDmg
+		dmgVariance += summonResult.DmgVariance
+		for tag, dmg := range summonResult.DotDmg {
+			dotDmg[tag] += dmg
+		}
+		hits = append(hits, summonResult.hits...)
+		for _, exp := range summonResult.Explanations {
+			explanations = append(explanations, summon.Name+" - "+exp)
+		}
+	}
+
+	totalHeal := 0.0
+	for heal, mult := range s.Heals {
+		healing, exp, err := CalcHeal(s.Character, s.LightCone, s.RelicBuild, *heal, s.HealTargetBuffs)
+		if err != nil {
+			return ScenarioResult{}, err
+		}
+		totalHeal += healing * mult
+		explanations = append(explanations, fmt.Sprintf("%s:\nHealing: %f\n\n%s", heal.Name, healing, exp))
+	}
+
+	totalShield := 0.0
+	shieldPerAction := make(map[*Shield]float64)
+	for shield, mult := range s.Shields {
+		strength, exp, err := CalcShield(s.Character, s.LightCone, s.RelicBuild, *shield)
+		if err != nil {
+			return ScenarioResult{}, err
+		}
+		totalShield += strength * mult
+		shieldPerAction[shield] = strength
+		explanations = append(explanations, fmt.Sprintf("%s:\nShield: %f\n\n%s", shield.Name, strength, exp))
+	}
+
+	return ScenarioResult{
+		TotalDmg:        totalDmg,
+		MinDmg:          minDmg,
+		MaxDmg:          maxDmg,
+		DmgVariance:     dmgVariance,
+		TotalHeal:       totalHeal,
+		TotalShield:     totalShield,
+		DotDmg:          dotDmg,
+		SummonDmg:       summonDmg,
+		EnemyDmg:        enemyDmg,
+		ShieldPerAction: shieldPerAction,
+		Explanations:    explanations,
+		hits:            hits,
+	}, nil
 }
 
+// ExpectedBounceHits returns the expected amount of hits of a Bounce attack that land on an enemy
+func ExpectedBounceHits(a Attack, enemyCount int, isFocused bool) float64 {
+	if enemyCount <= 0 || a.Hits <= 0 {
+		return 0
+	}
+	randomHits := float64(a.Hits)
+	if a.FirstHitFocused {
+		randomHits--
+	}
+	expectedHits := randomHits / float64(enemyCount)
+	if a.FirstHitFocused && isFocused {
+		expectedHits++
+	}
+	return expectedHits
+}
+
+// calcAvgHit calculates the average damage of a single hit, be it a normal attack or a break event
+func calcAvgHit(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack, hit HitContext, mode UptimeMode) (float64, string, error) {
+	c, lc, rb, e, a = applyConditions(c, lc, rb, e, a, hit)
+	isSplash := hit.IsSplash
+	return weightByUptime(c, lc, rb, e, a, mode, func(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) (float64, string, error) {
+		if a.DamageTags.Has(Break) {
+			return calcBreakDamage(c, lc, rb, e, a)
+		}
+		return calcAvgDamage(c, lc, rb, e, a, isSplash)
+	})
+}
+
+// CalcAvgDamage calculates the average damage of the attack.
+// Buffs with partial uptime are weighted exactly, see UptimeExact.
 func CalcAvgDamage(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack, isSplash bool) (float64, string, error) {
-	baseDamage, err := CalcBaseDamage(c, lc, rb, e, a, isSplash)
+	if err := validateHitBuffs(c, lc, rb, e, a); err != nil {
+		return 0, "", err
+	}
+	return calcAvgHit(c, lc, rb, e, a, HitContext{IsFocused: !isSplash, IsSplash: isSplash}, UptimeExact)
+}
+
+func calcAvgDamage(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack, isSplash bool) (float64, string, error) {
+	baseDamage, baseDamageExplanations, err := calcBaseDamage(c, lc, rb, e, a, isSplash)
 	if err != nil {
 		return 0, "", err
 	}
@@ -151,22 +546,22 @@ func CalcAvgDamage(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack,
 	defMult := CalcDefenseMultiplier(c, lc, rb, e, a)
 	vulnMult := CalcVulnerabilityMultiplier(e, a)
 	dmgReductionMult := CalcDmgReductionMultiplier(e, a)
+	toughnessMult := CalcToughnessMultiplier(e)
 
-	stats, err := CharacterStats(c, lc, rb, e, a)
-	if err != nil {
-		return 0, "", err
-	}
+	stats := characterStats(c, lc, rb, a)
 
 	explanation := fmt.Sprintf(
 		"Base Damage: %.2f\n"+
+			"%s\n"+
 			"Crit Multiplier: %.2f\n"+
 			"Damage Bonus Multiplier: %.2f\n"+
 			"Resistance Multiplier: %.2f\n"+
 			"Defense Multiplier: %.2f\n"+
 			"Vulnerability Multiplier: %.2f\n"+
-			"Damage Reduction Multiplier: %.2f\n\n"+
+			"Damage Reduction Multiplier: %.2f\n"+
+			"Toughness Multiplier: %.2f\n\n"+
 			"Stats:",
-		baseDamage, critMult, dmgBonusMult, resMult, defMult, vulnMult, dmgReductionMult)
+		baseDamage, strings.Join(baseDamageExplanations, "\n"), critMult, dmgBonusMult, resMult, defMult, vulnMult, dmgReductionMult, toughnessMult)
 	for _, stat := range AllStats() {
 		value, ok := stats[stat]
 		if !ok {
@@ -175,134 +570,266 @@ func CalcAvgDamage(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack,
 		explanation += fmt.Sprintf("\n%s: %.2f", stat, value)
 	}
 
-	return baseDamage * critMult * dmgBonusMult * resMult * defMult * vulnMult * dmgReductionMult, explanation, nil
+	conversions := c.ConversionBuffs(lc, rb, a.DamageTags, a.Element, a.Buffs)
+	if len(conversions) > 0 {
+		explanation += "\n\nConversions:"
+	}
+	for _, conversion := range conversions {
+		explanation += "\n" + conversion.String()
+	}
+
+	enemyStats := EnemyStats(e, a)
+	if len(enemyStats) > 0 {
+		explanation += "\n\nEnemy Stats:"
+	}
+	for _, stat := range AllEnemyStats() {
+		value, ok := enemyStats[stat]
+		if !ok {
+			continue
+		}
+		explanation += fmt.Sprintf("\n%s: %.2f", stat, value)
+	}
+	explanation += capsExplanation(c, lc, rb, e, a)
+
+	return baseDamage * critMult * dmgBonusMult * resMult * defMult * vulnMult * dmgReductionMult * toughnessMult, explanation, nil
 }
 
 func CharacterStats(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) (map[Stat]float64, error) {
+	if err := validateHitBuffs(c, lc, rb, e, a); err != nil {
+		return nil, err
+	}
+	return characterStats(c, lc, rb, a), nil
+}
+
+func characterStats(c Character, lc LightCone, rb RelicBuild, a Attack) map[Stat]float64 {
 	stats := make(map[Stat]float64)
 
 	for _, stat := range AllStats() {
-		value := c.FinalStatValue(lc, rb, stat, a.DamageTag, a.Element, nil)
+		value := c.FinalStatValue(lc, rb, stat, a.DamageTags, a.Element, nil)
 		if value != 0 {
 			stats[stat] = value
 		}
 	}
 
-	return stats, nil
+	return stats
+}
+
+// validateHitBuffs checks every buff that will be used to calculate the attack against the enemy.
+// Only the public entry points validate, the internal helpers trust their buffs.
+func validateHitBuffs(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) error {
+	return c.ValidateBuffs(lc, rb, append(append([]Buff{}, e.Buffs...), a.Buffs...))
 }
 
 func CalcBaseDamage(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack, isSplash bool) (float64, error) {
+	if err := validateHitBuffs(c, lc, rb, e, a); err != nil {
+		return 0, err
+	}
+	baseDamage, _, err := calcBaseDamage(c, lc, rb, e, a, isSplash)
+	return baseDamage, err
+}
+
+// calcBaseDamage also returns an explanation line for each of the base damage components
+func calcBaseDamage(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack, isSplash bool) (float64, []string, error) {
 	baseDamage := 0.0
-	mult := a.Multiplier
-	if isSplash {
-		mult = a.MultiplierSplash
+	explanations := []string{}
+	for _, scaling := range a.AllScalings() {
+		mult := scaling.Multiplier
+		if isSplash {
+			mult = scaling.MultiplierSplash
+		}
+
+		statValue := e.MaxHp
+		if scaling.Stat == EnemyMaxHp && e.MaxHp <= 0 {
+			return 0, nil, fmt.Errorf("%w: %s has %.2f max HP", ErrInvalidMaxHp, e.Name, e.MaxHp)
+		}
+		if scaling.Stat != EnemyMaxHp {
+			var err error
+			statValue, err = scalingStatValue(c, lc, rb, scaling.Stat, a.DamageTags, a.Element, a.Buffs)
+			if err != nil {
+				return 0, nil, err
+			}
+		}
+		baseDamage += statValue * mult / 100
+		explanations = append(explanations, fmt.Sprintf(" - %.2f%% of %s (%.2f): %.2f", mult, scaling.Stat, statValue, statValue*mult/100))
+	}
+
+	if a.FlatDamage != 0 {
+		baseDamage += a.FlatDamage
+		explanations = append(explanations, fmt.Sprintf(" - Flat Damage: %.2f", a.FlatDamage))
+	}
+
+	if a.CapMultiplier > 0 {
+		capValue, err := scalingStatValue(c, lc, rb, a.CapScalingStat, a.DamageTags, a.Element, a.Buffs)
+		if err != nil {
+			return 0, nil, err
+		}
+		if capValue*a.CapMultiplier/100 < baseDamage {
+			baseDamage = capValue * a.CapMultiplier / 100
+			explanations = append(explanations, fmt.Sprintf(" - Capped to %.2f%% of %s: %.2f", a.CapMultiplier, a.CapScalingStat, baseDamage))
+		}
 	}
-	switch a.ScalingStat {
-	case Hp:
-		baseDamage = c.FinalStatValue(lc, rb, Hp, a.DamageTag, a.Element, a.Buffs) * mult / 100
-	case Atk:
-		baseDamage = c.FinalStatValue(lc, rb, Atk, a.DamageTag, a.Element, a.Buffs) * mult / 100
-	case Def:
-		baseDamage = c.FinalStatValue(lc, rb, Def, a.DamageTag, a.Element, a.Buffs) * mult / 100
-	default:
-		return 0, ErrInvalidScalingStat
+
+	if a.DotStacks > 1 {
+		baseDamage *= float64(a.DotStacks)
+		explanations = append(explanations, fmt.Sprintf(" - DoT Stacks: %d", a.DotStacks))
 	}
-	return baseDamage, nil
+	return baseDamage, explanations, nil
 }
 
-func CalcAvgCritMultiplier(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) float64 {
-	if a.DamageTag == Dot {
-		return 1
+// scalingStatValue returns the final value of a stat that attacks, heals or shields can scale from
+func scalingStatValue(c Character, lc LightCone, rb RelicBuild, stat Stat, tags DamageTags, element Element, extraBuffs []Buff) (float64, error) {
+	switch stat {
+	case Hp, Atk, Def:
+		return c.FinalStatValue(lc, rb, stat, tags, element, extraBuffs), nil
 	}
-	critRate := c.FinalStatValue(lc, rb, CritRate, a.DamageTag, a.Element, a.Buffs)
-	critDamage := c.FinalStatValue(lc, rb, CritDmg, a.DamageTag, a.Element, a.Buffs)
-	critRate = math.Min(critRate, 100)
+	return 0, ErrInvalidScalingStat
+}
+
+func CalcAvgCritMultiplier(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) float64 {
+	critRate, critDamage := CalcCritStats(c, lc, rb, e, a)
 	return 1 + (critRate / 100 * critDamage / 100)
 }
 
+// CalcCritStats returns the crit rate, capped at 100, and the crit damage of the attack against the enemy.
+// DoTs can't crit, so both are zero for them.
+func CalcCritStats(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) (float64, float64) {
+	if a.DamageTags.IsDot() {
+		return 0, 0
+	}
+	critRate := c.FinalStatValue(lc, rb, CritRate, a.DamageTags, a.Element, a.Buffs) + e.StatValue(CritRateTaken, a)
+	critDamage := c.FinalStatValue(lc, rb, CritDmg, a.DamageTags, a.Element, a.Buffs) + e.StatValue(CritDmgTaken, a)
+	return math.Max(math.Min(critRate, 100), 0), critDamage
+}
+
 func CalcDmgBonusMult(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) float64 {
-	return 1 + c.FinalStatValue(lc, rb, DmgBonus, a.DamageTag, a.Element, a.Buffs)/100
+	return 1 + c.FinalStatValue(lc, rb, DmgBonus, a.DamageTags, a.Element, a.Buffs)/100
 }
 
-func CalcResistanceMultiplier(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) float64 {
+// RES multiplier bounds, the enemy's RES after RES PEN is clamped to [MinResistance, MaxResistance],
+// so the Resistance Multiplier ranges from 0.1 to 2.0
+const MinResistance = -100.0
+const MaxResistance = 90.0
+
+// CalcResistance returns the enemy's RES against the attack after RES PEN and RES shred, without clamping
+func CalcResistance(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) float64 {
 	res := 0.0
 
 	for _, buff := range e.Buffs {
-		if buff.Stat == ElementalRes && buff.Element.Is(a.Element) && buff.DamageTag.Is(a.DamageTag) {
-			res += buff.Value
+		if buff.Stat == ElementalRes && buff.Element.Is(a.Element) && a.DamageTags.Is(buff.DamageTag) {
+			res += buff.TotalValue()
 		}
-		if buff.Stat == ResShred && buff.Element.Is(a.Element) && buff.DamageTag.Is(a.DamageTag) {
-			res -= buff.Value
+		if buff.Stat == ResShred && buff.Element.Is(a.Element) && a.DamageTags.Is(buff.DamageTag) {
+			res -= buff.TotalValue()
 		}
 	}
 
 	for _, buff := range c.AllBuffs(lc, rb) {
-		if buff.Stat == ResPen && buff.Element.Is(a.Element) && buff.DamageTag.Is(a.DamageTag) {
-			res -= buff.Value
+		if buff.Stat == ResPen && buff.Element.Is(a.Element) && a.DamageTags.Is(buff.DamageTag) {
+			res -= buff.TotalValue()
 		}
 	}
 
 	for _, buff := range a.Buffs {
-		if buff.Stat == ResPen && buff.Element.Is(a.Element) && buff.DamageTag.Is(a.DamageTag) {
-			res -= buff.Value
+		if buff.Stat == ResPen && buff.Element.Is(a.Element) && a.DamageTags.Is(buff.DamageTag) {
+			res -= buff.TotalValue()
 		}
 	}
 
+	return res
+}
+
+func CalcResistanceMultiplier(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) float64 {
+	res := math.Min(math.Max(CalcResistance(c, lc, rb, e, a), MinResistance), MaxResistance)
 	return 1.0 - res/100
 }
 
-func CalcDefenseMultiplier(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) float64 {
-	flatDef := 0.0
-	defPct := 0.0
+// WastedResPen returns how much RES PEN and RES shred is lost because the enemy's RES hit MinResistance
+func WastedResPen(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) float64 {
+	return math.Max(MinResistance-CalcResistance(c, lc, rb, e, a), 0)
+}
+
+// CalcDefReduction returns the total DEF shred and DEF ignore applied to the enemy by the attack
+func CalcDefReduction(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) float64 {
 	defReduction := 0.0
-	baseDef := 200.0 + 10.0*float64(e.Level)
 
 	for _, buff := range e.Buffs {
-		if buff.Stat == Def {
-			flatDef += buff.Value
-		}
-		if buff.Stat == DefPct {
-			defPct += buff.Value
-		}
-		if buff.Stat == DefShred && buff.Element.Is(a.Element) && buff.DamageTag.Is(a.DamageTag) {
-			defReduction += buff.Value
+		if buff.Stat == DefShred && buff.Element.Is(a.Element) && a.DamageTags.Is(buff.DamageTag) {
+			defReduction += buff.TotalValue()
 		}
 	}
 
 	for _, buff := range c.AllBuffs(lc, rb) {
-		if buff.Stat == DefIgnore && buff.Element.Is(a.Element) && buff.DamageTag.Is(a.DamageTag) {
-			defReduction += buff.Value
+		if buff.Stat == DefIgnore && buff.Element.Is(a.Element) && a.DamageTags.Is(buff.DamageTag) {
+			defReduction += buff.TotalValue()
 		}
 	}
 
 	for _, buff := range a.Buffs {
-		if buff.Stat == DefIgnore && buff.Element.Is(a.Element) && buff.DamageTag.Is(a.DamageTag) {
-			defReduction += buff.Value
+		if buff.Stat == DefIgnore && buff.Element.Is(a.Element) && a.DamageTags.Is(buff.DamageTag) {
+			defReduction += buff.TotalValue()
 		}
 	}
 
-	totalDef := baseDef*(1+defPct/100-defReduction/100) + flatDef
-	totalDef = math.Max(totalDef, 0)
-
-	return 1 - (totalDef / (totalDef + 200.0 + 10.0*float64(c.Level)))
+	return defReduction
 }
 
-func CalcVulnerabilityMultiplier(e Enemy, a Attack) float64 {
-	vuln := 0.0
+// maxDefReduction returns the DEF reduction that brings the enemy's DEF% to zero, any reduction above it is wasted
+func maxDefReduction(e Enemy) float64 {
+	defPct := 0.0
 	for _, buff := range e.Buffs {
-		if buff.Stat == Vulnerability && buff.Element.Is(a.Element) && buff.DamageTag.Is(a.DamageTag) {
-			vuln += buff.Value
+		if buff.Stat == DefPct {
+			defPct += buff.TotalValue()
 		}
 	}
-	return 1.0 + vuln/100
+	return math.Max(100+defPct, 0)
 }
 
-func CalcDmgReductionMultiplier(e Enemy, a Attack) float64 {
-	dmgReduction := 0.0
+func CalcDefenseMultiplier(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) float64 {
+	flatDef := 0.0
+	baseDef := 200.0 + 10.0*float64(e.Level)
+
 	for _, buff := range e.Buffs {
-		if buff.Stat == DmgReduction && buff.Element.Is(a.Element) && buff.DamageTag.Is(a.DamageTag) {
-			dmgReduction += buff.Value
+		if buff.Stat == Def {
+			flatDef += buff.TotalValue()
 		}
 	}
-	return 1.0 - dmgReduction/100
+
+	maxReduction := maxDefReduction(e)
+	defReduction := math.Min(CalcDefReduction(c, lc, rb, e, a), maxReduction)
+	totalDef := baseDef*(maxReduction-defReduction)/100 + flatDef
+	totalDef = math.Max(totalDef, 0)
+
+	return 1 - (totalDef / (totalDef + 200.0 + 10.0*float64(c.Level)))
+}
+
+// WastedDefReduction returns how much DEF shred and DEF ignore is lost because the enemy's DEF% already reached zero
+func WastedDefReduction(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) float64 {
+	return math.Max(CalcDefReduction(c, lc, rb, e, a)-maxDefReduction(e), 0)
+}
+
+// capsExplanation returns a line for each RES or DEF cap hit by the attack, or an empty string if none is hit
+func capsExplanation(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) string {
+	explanation := ""
+	res := CalcResistance(c, lc, rb, e, a)
+	if res < MinResistance {
+		explanation += fmt.Sprintf("\nResistance capped at %.0f%% (%.2f%% RES PEN wasted)", MinResistance, WastedResPen(c, lc, rb, e, a))
+	}
+	if res > MaxResistance {
+		explanation += fmt.Sprintf("\nResistance capped at %.0f%% (%.2f%% RES ignored)", MaxResistance, res-MaxResistance)
+	}
+	if wasted := WastedDefReduction(c, lc, rb, e, a); wasted > 0 {
+		explanation += fmt.Sprintf("\nDEF reduction capped at %.0f%% (%.2f%% DEF reduction wasted)", maxDefReduction(e), wasted)
+	}
+	if explanation == "" {
+		return ""
+	}
+	return "\n\nCaps:" + explanation
+}
+
+func CalcVulnerabilityMultiplier(e Enemy, a Attack) float64 {
+	return 1.0 + e.StatValue(Vulnerability, a)/100
+}
+
+func CalcDmgReductionMultiplier(e Enemy, a Attack) float64 {
+	return 1.0 - e.StatValue(DmgReduction, a)/100
 }
@@ -1,7 +1,11 @@
 package hsrtct_test
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/j4rv/hsr-tct/pkg/hsrtct"
@@ -36,7 +40,7 @@ func GetAeonLC() hsrtct.LightCone {
 		BaseAtk: 529,
 		BaseDef: 396,
 		Buffs: []hsrtct.Buff{
-			{Stat: hsrtct.AtkPct, Value: 16 * 4},
+			{Name: "Aeon", Stat: hsrtct.AtkPct, ValuePerStack: 16, Stacks: 4, MaxStacks: 4},
 			{Stat: hsrtct.DmgBonus, Value: 24},
 		},
 	}
@@ -88,7 +92,7 @@ func TestCalcAvgDamageUltimate(t *testing.T) {
 		ScalingStat: hsrtct.Atk,
 		Multiplier:  432 + 110,
 		Element:     hsrtct.Fire,
-		DamageTag:   hsrtct.Ultimate,
+		DamageTags:  hsrtct.DamageTags{hsrtct.Ultimate},
 	}
 
 	scn := hsrtct.Scenario{
@@ -102,8 +106,8 @@ func TestCalcAvgDamageUltimate(t *testing.T) {
 
 	scnResult, err := hsrtct.CalcAvgDmgScenario(scn)
 	assertNilError(t, err)
-	if int(scnResult.TotalDmg) != 41425 {
-		t.Fatalf("Expected damage to be 41425, got %v", scnResult.TotalDmg)
+	if int(scnResult.TotalDmg) != 37282 {
+		t.Fatalf("Expected damage to be 37282, got %v", scnResult.TotalDmg)
 	}
 }
 
@@ -122,7 +126,7 @@ func TestCalcAvgDamageMultipleEnemies(t *testing.T) {
 		Multiplier:       308 + 110,
 		MultiplierSplash: 88 + 110,
 		Element:          hsrtct.Fire,
-		DamageTag:        hsrtct.Skill,
+		DamageTags:       hsrtct.DamageTags{hsrtct.Skill},
 	}
 
 	scn := hsrtct.Scenario{
@@ -138,8 +142,25 @@ func TestCalcAvgDamageMultipleEnemies(t *testing.T) {
 	assertNilError(t, err)
 
 	damage := scnResult.TotalDmg
-	if int(damage) != 91656 {
-		t.Fatalf("Expected damage to be 91656, got %v", damage)
+	if int(damage) != 82490 {
+		t.Fatalf("Expected damage to be 82490, got %v", damage)
+	}
+}
+
+func TestCalcAvgDamageBrokenEnemy(t *testing.T) {
+	enemy := GetBasicEnemy()
+	enemy.Broken = true
+	attack := hsrtct.Attack{
+		ScalingStat: hsrtct.Atk,
+		Multiplier:  432 + 110,
+		Element:     hsrtct.Fire,
+		DamageTags:  hsrtct.DamageTags{hsrtct.Ultimate},
+	}
+
+	dmg, _, err := hsrtct.CalcAvgDamage(GetHookCharacter(), GetAeonLC(), GetHookRelicBuild(), enemy, attack, false)
+	assertNilError(t, err)
+	if int(dmg) != 41425 {
+		t.Fatalf("Expected damage to be 41425, got %v", dmg)
 	}
 }
 
@@ -150,6 +171,243 @@ func TestExplainFinalStats(t *testing.T) {
 	log.Println(hsrtct.CharacterStats(hook, lc, rb, GetBasicEnemy(), hsrtct.Attack{}))
 }
 
+func TestCalcAvgCritMultiplierWithCritTaken(t *testing.T) {
+	enemy := GetBasicEnemy()
+	enemy.Buffs = append(enemy.Buffs,
+		hsrtct.Buff{Stat: hsrtct.CritRateTaken, Value: 30},
+		hsrtct.Buff{Stat: hsrtct.CritDmgTaken, Value: 20, DamageTag: hsrtct.FollowUp},
+	)
+	ultimate := hsrtct.Attack{ScalingStat: hsrtct.Atk, Element: hsrtct.Fire, DamageTags: hsrtct.DamageTags{hsrtct.Ultimate}}
+	followUp := hsrtct.Attack{ScalingStat: hsrtct.Atk, Element: hsrtct.Fire, DamageTags: hsrtct.DamageTags{hsrtct.FollowUp}}
+
+	// Crit Rate is capped to 100%: 74.6 + 30
+	critMult := hsrtct.CalcAvgCritMultiplier(GetHookCharacter(), GetAeonLC(), GetHookRelicBuild(), enemy, ultimate)
+	if fmt.Sprintf("%.4f", critMult) != "2.3326" {
+		t.Fatalf("Expected crit multiplier to be 2.3326, got %v", critMult)
+	}
+	critMult = hsrtct.CalcAvgCritMultiplier(GetHookCharacter(), GetAeonLC(), GetHookRelicBuild(), enemy, followUp)
+	if fmt.Sprintf("%.4f", critMult) != "2.5326" {
+		t.Fatalf("Expected crit multiplier to be 2.5326, got %v", critMult)
+	}
+}
+
+func TestMultiTagAttack(t *testing.T) {
+	hook := GetHookCharacter()
+	hook.Buffs = append(hook.Buffs,
+		hsrtct.Buff{Stat: hsrtct.DmgBonus, Value: 10, DamageTag: hsrtct.FollowUp},
+		hsrtct.Buff{Stat: hsrtct.DmgBonus, Value: 30, DamageTag: hsrtct.Ultimate},
+	)
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	enemy := GetBasicEnemy()
+
+	followUp := hsrtct.Attack{ScalingStat: hsrtct.Atk, Element: hsrtct.Fire, DamageTags: hsrtct.ParseDamageTags("FollowUp")}
+	followUpUltimate := hsrtct.Attack{ScalingStat: hsrtct.Atk, Element: hsrtct.Fire, DamageTags: hsrtct.ParseDamageTags("FollowUp, Ultimate")}
+
+	bonus := hsrtct.CalcDmgBonusMult(hook, lc, rb, enemy, followUp)
+	multiTagBonus := hsrtct.CalcDmgBonusMult(hook, lc, rb, enemy, followUpUltimate)
+	if fmt.Sprintf("%.2f", multiTagBonus-bonus) != "0.30" {
+		t.Fatalf("Expected the Ultimate buff to also apply, got %v and %v", bonus, multiTagBonus)
+	}
+}
+
+func TestCalcAvgDamageBounce(t *testing.T) {
+	hook := GetHookCharacter()
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	debuffedEnemy := GetBasicEnemy()
+	debuffedEnemy.Buffs = append(debuffedEnemy.Buffs, hsrtct.Buff{Stat: hsrtct.Vulnerability, Value: 100})
+
+	bounce := hsrtct.Attack{
+		ScalingStat:     hsrtct.Atk,
+		AttackAOE:       hsrtct.Bounce,
+		Multiplier:      100,
+		Hits:            5,
+		FirstHitFocused: true,
+		Element:         hsrtct.Fire,
+		DamageTags:      hsrtct.DamageTags{hsrtct.FollowUp},
+	}
+	singleHit := bounce
+	singleHit.AttackAOE = hsrtct.Single
+
+	hitDmg, _, err := hsrtct.CalcAvgDamage(hook, lc, rb, GetBasicEnemy(), singleHit, false)
+	assertNilError(t, err)
+
+	scn := hsrtct.Scenario{
+		Character:    hook,
+		LightCone:    lc,
+		RelicBuild:   rb,
+		Enemies:      []hsrtct.Enemy{GetBasicEnemy(), debuffedEnemy},
+		Attacks:      map[*hsrtct.Attack]float64{&bounce: 1},
+		FocusedEnemy: 1,
+	}
+	scnResult, err := hsrtct.CalcAvgDmgScenario(scn)
+	assertNilError(t, err)
+
+	// The focused enemy takes 1 + 4/2 hits with double damage, the other one takes 4/2 hits
+	expected := hitDmg*3*2 + hitDmg*2
+	if int(scnResult.TotalDmg) != int(expected) {
+		t.Fatalf("Expected damage to be %v, got %v", expected, scnResult.TotalDmg)
+	}
+}
+
+func TestCalcBaseDamageMultipleScalings(t *testing.T) {
+	hook := GetHookCharacter()
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	enemy := GetBasicEnemy()
+
+	attack := hsrtct.Attack{
+		ScalingStat: hsrtct.Atk,
+		Multiplier:  100,
+		Scalings:    []hsrtct.Scaling{{Stat: hsrtct.Def, Multiplier: 50}},
+		FlatDamage:  1000,
+		Element:     hsrtct.Fire,
+	}
+	dmg, err := hsrtct.CalcBaseDamage(hook, lc, rb, enemy, attack, false)
+	assertNilError(t, err)
+	// 4029.68 ATK + 50% of 748 DEF + 1000
+	if int(dmg) != 5403 {
+		t.Fatalf("Expected base damage to be 5403, got %v", dmg)
+	}
+
+	attack.Scalings = append(attack.Scalings, hsrtct.Scaling{Stat: hsrtct.CritDmg, Multiplier: 100})
+	_, err = hsrtct.CalcBaseDamage(hook, lc, rb, enemy, attack, false)
+	if err != hsrtct.ErrInvalidScalingStat {
+		t.Fatalf("Expected ErrInvalidScalingStat, got '%v'", err)
+	}
+}
+
+func TestCalcAvgDmgScenarioStackOverrides(t *testing.T) {
+	attack := hsrtct.Attack{
+		ScalingStat: hsrtct.Atk,
+		Multiplier:  432 + 110,
+		Element:     hsrtct.Fire,
+		DamageTags:  hsrtct.DamageTags{hsrtct.Ultimate},
+	}
+	firstAttack := attack
+
+	scn := hsrtct.Scenario{
+		Character:      GetHookCharacter(),
+		LightCone:      GetAeonLC(),
+		RelicBuild:     GetHookRelicBuild(),
+		Enemies:        []hsrtct.Enemy{GetBasicEnemy()},
+		Attacks:        map[*hsrtct.Attack]float64{&firstAttack: 1},
+		StackOverrides: map[*hsrtct.Attack]map[string]int{&firstAttack: {"Aeon": 1}},
+	}
+
+	scnResult, err := hsrtct.CalcAvgDmgScenario(scn)
+	assertNilError(t, err)
+	// Same as TestCalcAvgDamageUltimate, with 48% less ATK%
+	if int(scnResult.TotalDmg) != 32193 {
+		t.Fatalf("Expected damage to be 32193, got %v", scnResult.TotalDmg)
+	}
+
+	scn.StackOverrides[&firstAttack]["Aeon"] = 5
+	_, err = hsrtct.CalcAvgDmgScenario(scn)
+	if !errors.Is(err, hsrtct.ErrInvalidStacks) {
+		t.Fatalf("Expected ErrInvalidStacks, got '%v'", err)
+	}
+}
+
+func TestResistanceMultiplierCaps(t *testing.T) {
+	hook := GetHookCharacter()
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	attack := hsrtct.Attack{
+		Element:    hsrtct.Fire,
+		DamageTags: hsrtct.DamageTags{hsrtct.Skill},
+		Buffs:      []hsrtct.Buff{{Stat: hsrtct.ResPen, Value: 150}},
+	}
+
+	enemy := GetBasicEnemy()
+	enemy.Buffs = append(enemy.Buffs, hsrtct.Buff{Stat: hsrtct.ElementalRes, Value: 20})
+	if mult := hsrtct.CalcResistanceMultiplier(hook, lc, rb, enemy, attack); mult != 2 {
+		t.Fatalf("Expected resistance multiplier to be capped at 2, got %v", mult)
+	}
+	if wasted := hsrtct.WastedResPen(hook, lc, rb, enemy, attack); wasted != 30 {
+		t.Fatalf("Expected 30 wasted RES PEN, got %v", wasted)
+	}
+
+	attack.Buffs = nil
+	enemy.Buffs = []hsrtct.Buff{{Stat: hsrtct.ElementalRes, Value: 120}}
+	if mult := hsrtct.CalcResistanceMultiplier(hook, lc, rb, enemy, attack); math.Abs(mult-0.1) > 1e-9 {
+		t.Fatalf("Expected resistance multiplier to be floored at 0.1, got %v", mult)
+	}
+	if wasted := hsrtct.WastedResPen(hook, lc, rb, enemy, attack); wasted != 0 {
+		t.Fatalf("Expected no wasted RES PEN, got %v", wasted)
+	}
+}
+
+func TestDefenseMultiplierCap(t *testing.T) {
+	hook := GetHookCharacter()
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	attack := hsrtct.Attack{
+		ScalingStat: hsrtct.Atk,
+		Multiplier:  100,
+		Element:     hsrtct.Fire,
+		DamageTags:  hsrtct.DamageTags{hsrtct.Skill},
+		Buffs:       []hsrtct.Buff{{Stat: hsrtct.DefIgnore, Value: 50}},
+	}
+	enemy := GetBasicEnemy()
+	enemy.Buffs = append(enemy.Buffs, hsrtct.Buff{Stat: hsrtct.DefShred, Value: 80})
+
+	if mult := hsrtct.CalcDefenseMultiplier(hook, lc, rb, enemy, attack); mult != 1 {
+		t.Fatalf("Expected defense multiplier to be 1 with DEF reduction over the cap, got %v", mult)
+	}
+	expectedWaste := hsrtct.CalcDefReduction(hook, lc, rb, enemy, attack) - 100
+	if wasted := hsrtct.WastedDefReduction(hook, lc, rb, enemy, attack); wasted != expectedWaste || wasted < 30 {
+		t.Fatalf("Expected %v wasted DEF reduction, got %v", expectedWaste, wasted)
+	}
+
+	_, explanation, err := hsrtct.CalcAvgDamage(hook, lc, rb, enemy, attack, false)
+	assertNilError(t, err)
+	if !strings.Contains(explanation, "DEF reduction capped") {
+		t.Fatalf("Expected the explanation to report the DEF cap, got %v", explanation)
+	}
+}
+
+func TestCalcAvgDmgScenarioBounceWithoutHits(t *testing.T) {
+	bounce := hsrtct.Attack{ScalingStat: hsrtct.Atk, Multiplier: 100, AttackAOE: hsrtct.Bounce, FirstHitFocused: true}
+	scn := hsrtct.Scenario{
+		Character:  GetHookCharacter(),
+		LightCone:  GetAeonLC(),
+		RelicBuild: GetHookRelicBuild(),
+		Enemies:    []hsrtct.Enemy{GetBasicEnemy(), GetBasicEnemy()},
+		Attacks:    map[*hsrtct.Attack]float64{&bounce: 1},
+	}
+	_, err := hsrtct.CalcAvgDmgScenario(scn)
+	if !errors.Is(err, hsrtct.ErrInvalidHits) {
+		t.Fatalf("Expected ErrInvalidHits, got %v", err)
+	}
+}
+
+func TestInvalidStacksOutsideScenarios(t *testing.T) {
+	hook := GetHookCharacter()
+	lc := GetAeonLC()
+	lc.Buffs[0].Stacks = 5
+	rb := GetHookRelicBuild()
+	attack := hsrtct.Attack{ScalingStat: hsrtct.Atk, Multiplier: 100}
+
+	_, _, err := hsrtct.CalcAvgDamage(hook, lc, rb, GetBasicEnemy(), attack, false)
+	if !errors.Is(err, hsrtct.ErrInvalidStacks) {
+		t.Fatalf("Expected ErrInvalidStacks from CalcAvgDamage, got '%v'", err)
+	}
+	_, err = hsrtct.CharacterStats(hook, lc, rb, GetBasicEnemy(), attack)
+	if !errors.Is(err, hsrtct.ErrInvalidStacks) {
+		t.Fatalf("Expected ErrInvalidStacks from CharacterStats, got '%v'", err)
+	}
+	_, _, err = hsrtct.CalcHeal(hook, lc, rb, hsrtct.Heal{ScalingStat: hsrtct.Atk, Multiplier: 10}, nil)
+	if !errors.Is(err, hsrtct.ErrInvalidStacks) {
+		t.Fatalf("Expected ErrInvalidStacks from CalcHeal, got '%v'", err)
+	}
+	_, _, err = hsrtct.CalcShield(hook, lc, rb, hsrtct.Shield{ScalingStat: hsrtct.Def, Multiplier: 10})
+	if !errors.Is(err, hsrtct.ErrInvalidStacks) {
+		t.Fatalf("Expected ErrInvalidStacks from CalcShield, got '%v'", err)
+	}
+}
+
 func assertNilError(t *testing.T, err error) {
 	if err != nil {
 		t.Fatalf("Expected nil error, got '%v'", err)
@@ -0,0 +1,91 @@
+package hsrtct
+
+import (
+	"fmt"
+	"math"
+)
+
+// DamageDistribution describes the possible outcomes of a single hit.
+// NonCrit and Crit are the damage dealt when the hit doesn't crit and when it does,
+// Expected is the average damage, the same value returned by CalcAvgDamage.
+// Hits that can't crit (DoTs, break damage) have a zero CritRate and the same NonCrit and Crit values.
+type DamageDistribution struct {
+	NonCrit  float64
+	Crit     float64
+	Expected float64
+	Variance float64
+	CritRate float64
+}
+
+func (d DamageDistribution) StdDev() float64 {
+	return math.Sqrt(d.Variance)
+}
+
+func (d DamageDistribution) String() string {
+	return fmt.Sprintf("Non-Crit Damage: %.2f\nCrit Damage: %.2f\nExpected Damage: %.2f\nCrit Rate: %.2f\nStandard Deviation: %.2f",
+		d.NonCrit, d.Crit, d.Expected, d.CritRate, d.StdDev())
+}
+
+// CalcDamageDistribution calculates the non-crit, crit and expected damage of the attack, and its variance.
+// Buffs with partial uptime are weighted exactly, see UptimeExact.
+func CalcDamageDistribution(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack, isSplash bool) (DamageDistribution, string, error) {
+	if err := validateHitBuffs(c, lc, rb, e, a); err != nil {
+		return DamageDistribution{}, "", err
+	}
+	return calcHitDistribution(c, lc, rb, e, a, HitContext{IsFocused: !isSplash, IsSplash: isSplash}, UptimeExact)
+}
+
+func calcHitDistribution(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack, hit HitContext, mode UptimeMode) (DamageDistribution, string, error) {
+	expected, explanation, err := calcAvgHit(c, lc, rb, e, a, hit, mode)
+	if err != nil {
+		return DamageDistribution{}, "", err
+	}
+
+	// Every outcome is weighted by uptime on its own, the variance comes from the weighted second moment
+	c, lc, rb, e, a = applyConditions(c, lc, rb, e, a, hit)
+	weighted := func(pick func(nonCrit, crit, critRate float64) float64) (float64, error) {
+		value, _, err := weightByUptime(c, lc, rb, e, a, mode, func(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) (float64, string, error) {
+			nonCrit, crit, critRate, err := calcHitOutcomes(c, lc, rb, e, a, hit.IsSplash)
+			return pick(nonCrit, crit, critRate), "", err
+		})
+		return value, err
+	}
+
+	dist := DamageDistribution{Expected: expected}
+	if dist.NonCrit, err = weighted(func(nonCrit, crit, critRate float64) float64 { return nonCrit }); err != nil {
+		return DamageDistribution{}, "", err
+	}
+	if dist.Crit, err = weighted(func(nonCrit, crit, critRate float64) float64 { return crit }); err != nil {
+		return DamageDistribution{}, "", err
+	}
+	if dist.CritRate, err = weighted(func(nonCrit, crit, critRate float64) float64 { return critRate }); err != nil {
+		return DamageDistribution{}, "", err
+	}
+	secondMoment, err := weighted(func(nonCrit, crit, critRate float64) float64 {
+		return critRate/100*crit*crit + (1-critRate/100)*nonCrit*nonCrit
+	})
+	if err != nil {
+		return DamageDistribution{}, "", err
+	}
+	dist.Variance = math.Max(secondMoment-expected*expected, 0)
+
+	return dist, explanation, nil
+}
+
+// calcHitOutcomes returns the non-crit and crit damage of a hit, and its crit rate, with every buff fully active
+func calcHitOutcomes(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack, isSplash bool) (float64, float64, float64, error) {
+	if a.DamageTags.Has(Break) {
+		dmg, _, err := calcBreakDamage(c, lc, rb, e, a)
+		return dmg, dmg, 0, err
+	}
+	avg, _, err := calcAvgDamage(c, lc, rb, e, a, isSplash)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	critRate, critDmg := CalcCritStats(c, lc, rb, e, a)
+	nonCrit := avg / (1 + critRate/100*critDmg/100)
+	if critRate <= 0 {
+		return nonCrit, nonCrit, 0, nil
+	}
+	return nonCrit, nonCrit * (1 + critDmg/100), critRate, nil
+}
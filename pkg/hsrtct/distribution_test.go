@@ -0,0 +1,102 @@
+package hsrtct_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/j4rv/hsr-tct/pkg/hsrtct"
+)
+
+func TestCalcDamageDistribution(t *testing.T) {
+	hook := GetHookCharacter()
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	enemy := GetBasicEnemy()
+	attack := hsrtct.Attack{
+		ScalingStat: hsrtct.Atk,
+		Multiplier:  100,
+		Element:     hsrtct.Fire,
+		DamageTags:  hsrtct.DamageTags{hsrtct.Skill},
+	}
+
+	avg, _, err := hsrtct.CalcAvgDamage(hook, lc, rb, enemy, attack, false)
+	assertNilError(t, err)
+	dist, _, err := hsrtct.CalcDamageDistribution(hook, lc, rb, enemy, attack, false)
+	assertNilError(t, err)
+
+	if math.Abs(dist.Expected-avg) > 1e-6 {
+		t.Fatalf("Expected the expected damage to be %v, got %v", avg, dist.Expected)
+	}
+	if math.Abs(dist.CritRate-74.6) > 1e-6 {
+		t.Fatalf("Expected crit rate to be 74.6, got %v", dist.CritRate)
+	}
+	if math.Abs(dist.Crit-dist.NonCrit*2.3326) > 1e-6 {
+		t.Fatalf("Expected crit damage to be %v, got %v", dist.NonCrit*2.3326, dist.Crit)
+	}
+	expectedFromOutcomes := dist.NonCrit*0.254 + dist.Crit*0.746
+	if math.Abs(dist.Expected-expectedFromOutcomes) > 1e-6 {
+		t.Fatalf("Expected damage %v doesn't match the outcomes %v", dist.Expected, expectedFromOutcomes)
+	}
+	expectedVariance := 0.746 * 0.254 * math.Pow(dist.Crit-dist.NonCrit, 2)
+	if math.Abs(dist.Variance-expectedVariance) > 1e-3 {
+		t.Fatalf("Expected variance to be %v, got %v", expectedVariance, dist.Variance)
+	}
+}
+
+func TestCalcDamageDistributionPartialUptime(t *testing.T) {
+	hook := GetHookCharacter()
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	enemy := GetBasicEnemy()
+	attack := hsrtct.Attack{
+		ScalingStat: hsrtct.Atk,
+		Multiplier:  100,
+		Element:     hsrtct.Fire,
+		DamageTags:  hsrtct.DamageTags{hsrtct.Skill},
+		Buffs:       []hsrtct.Buff{{Stat: hsrtct.CritRate, Value: 25.4, Uptime: 0.5}},
+	}
+
+	dist, _, err := hsrtct.CalcDamageDistribution(hook, lc, rb, enemy, attack, false)
+	assertNilError(t, err)
+	if math.Abs(dist.CritRate-(74.6+12.7)) > 1e-6 {
+		t.Fatalf("Expected crit rate to be weighted by uptime, got %v", dist.CritRate)
+	}
+	if dist.Variance <= 0 {
+		t.Fatalf("Expected a positive variance, got %v", dist.Variance)
+	}
+}
+
+func TestCalcAvgDmgScenarioDistribution(t *testing.T) {
+	hook := GetHookCharacter()
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	attack := hsrtct.Attack{
+		ScalingStat: hsrtct.Atk,
+		Multiplier:  100,
+		Element:     hsrtct.Fire,
+		DamageTags:  hsrtct.DamageTags{hsrtct.Skill},
+		AttackAOE:   hsrtct.All,
+	}
+	dist, _, err := hsrtct.CalcDamageDistribution(hook, lc, rb, GetBasicEnemy(), attack, false)
+	assertNilError(t, err)
+
+	scn := hsrtct.Scenario{
+		Character:  hook,
+		LightCone:  lc,
+		RelicBuild: rb,
+		Enemies:    []hsrtct.Enemy{GetBasicEnemy(), GetBasicEnemy()},
+		Attacks:    map[*hsrtct.Attack]float64{&attack: 3},
+	}
+	result, err := hsrtct.CalcAvgDmgScenario(scn)
+	assertNilError(t, err)
+
+	if math.Abs(result.MinDmg-dist.NonCrit*6) > 1e-6 {
+		t.Fatalf("Expected min damage to be %v, got %v", dist.NonCrit*6, result.MinDmg)
+	}
+	if math.Abs(result.MaxDmg-dist.Crit*6) > 1e-6 {
+		t.Fatalf("Expected max damage to be %v, got %v", dist.Crit*6, result.MaxDmg)
+	}
+	if math.Abs(result.DmgVariance-dist.Variance*6) > 1e-3 {
+		t.Fatalf("Expected variance to be %v, got %v", dist.Variance*6, result.DmgVariance)
+	}
+}
@@ -0,0 +1,88 @@
+package hsrtct_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/j4rv/hsr-tct/pkg/hsrtct"
+)
+
+func TestDotKindBuffs(t *testing.T) {
+	hook := GetHookCharacter()
+	hook.Buffs = append(hook.Buffs, hsrtct.Buff{Stat: hsrtct.DmgBonus, Value: 50, DamageTag: hsrtct.Burn})
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	enemy := GetBasicEnemy()
+
+	burn := hsrtct.Attack{ScalingStat: hsrtct.Atk, Multiplier: 100, Element: hsrtct.Fire, DamageTags: hsrtct.DamageTags{hsrtct.Burn}}
+	shock := hsrtct.Attack{ScalingStat: hsrtct.Atk, Multiplier: 100, Element: hsrtct.Fire, DamageTags: hsrtct.DamageTags{hsrtct.Shock}}
+	dot := hsrtct.Attack{ScalingStat: hsrtct.Atk, Multiplier: 100, Element: hsrtct.Fire, DamageTags: hsrtct.DamageTags{hsrtct.Dot}}
+
+	burnBonus := hsrtct.CalcDmgBonusMult(hook, lc, rb, enemy, burn)
+	shockBonus := hsrtct.CalcDmgBonusMult(hook, lc, rb, enemy, shock)
+	dotBonus := hsrtct.CalcDmgBonusMult(hook, lc, rb, enemy, dot)
+	if fmt.Sprintf("%.2f", burnBonus-shockBonus) != "0.50" {
+		t.Fatalf("Expected Burn buffs to only apply to Burn, got %v and %v", burnBonus, shockBonus)
+	}
+	if dotBonus != shockBonus {
+		t.Fatalf("Expected Burn buffs to not apply to generic DoTs, got %v", dotBonus)
+	}
+
+	// Generic DoT buffs apply to every DoT kind
+	hook.Buffs = append(hook.Buffs, hsrtct.Buff{Stat: hsrtct.DmgBonus, Value: 20, DamageTag: hsrtct.Dot})
+	if diff := hsrtct.CalcDmgBonusMult(hook, lc, rb, enemy, shock) - shockBonus; fmt.Sprintf("%.2f", diff) != "0.20" {
+		t.Fatalf("Expected Dot buffs to apply to Shock, got %v", diff)
+	}
+	if critMult := hsrtct.CalcAvgCritMultiplier(hook, lc, rb, enemy, burn); critMult != 1 {
+		t.Fatalf("Expected DoTs to not crit, got %v", critMult)
+	}
+}
+
+func TestWindShearAndBleedBaseDamage(t *testing.T) {
+	hook := GetHookCharacter()
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	enemy := GetBasicEnemy()
+	enemy.MaxHp = 50000
+
+	windShear := hsrtct.Attack{ScalingStat: hsrtct.Atk, Multiplier: 10, DotStacks: 5, DamageTags: hsrtct.DamageTags{hsrtct.WindShear}}
+	dmg, err := hsrtct.CalcBaseDamage(hook, lc, rb, enemy, windShear, false)
+	assertNilError(t, err)
+	// 4029.68 ATK * 10% * 5 stacks
+	if int(dmg) != 2014 {
+		t.Fatalf("Expected Wind Shear damage to be 2014, got %v", dmg)
+	}
+
+	bleed := hsrtct.Attack{
+		ScalingStat:    hsrtct.EnemyMaxHp,
+		Multiplier:     24,
+		CapScalingStat: hsrtct.Atk,
+		CapMultiplier:  338,
+		DamageTags:     hsrtct.DamageTags{hsrtct.Bleed},
+	}
+	dmg, err = hsrtct.CalcBaseDamage(hook, lc, rb, enemy, bleed, false)
+	assertNilError(t, err)
+	if dmg != 12000 {
+		t.Fatalf("Expected Bleed damage to be 12000, got %v", dmg)
+	}
+	enemy.MaxHp = 1000000
+	dmg, err = hsrtct.CalcBaseDamage(hook, lc, rb, enemy, bleed, false)
+	assertNilError(t, err)
+	// 4029.68 ATK * 338%
+	if int(dmg) != 13620 {
+		t.Fatalf("Expected Bleed damage to be capped to 13620, got %v", dmg)
+	}
+}
+
+func TestBleedWithoutMaxHp(t *testing.T) {
+	bleed := hsrtct.Attack{
+		ScalingStat: hsrtct.EnemyMaxHp,
+		Multiplier:  24,
+		DamageTags:  hsrtct.DamageTags{hsrtct.Bleed},
+	}
+	_, _, err := hsrtct.CalcAvgDamage(GetHookCharacter(), GetAeonLC(), GetHookRelicBuild(), GetBasicEnemy(), bleed, false)
+	if !errors.Is(err, hsrtct.ErrInvalidMaxHp) {
+		t.Fatalf("Expected ErrInvalidMaxHp, got %v", err)
+	}
+}
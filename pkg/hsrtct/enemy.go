@@ -1,8 +1,69 @@
 package hsrtct
 
+import "errors"
+
+var ErrInvalidMaxHp = errors.New("invalid enemy max HP")
+
+// Enemy is a target of the character's attacks.
+// Toughness uses the same units as the attacks' toughness reduction.
+// If Weaknesses is empty, the enemy is weak to every element: it can be broken by any element
+// and meets every TargetWeak condition.
+// HpPct is the enemy's current HP as a percentage of MaxHp, zero means full HP.
+// Debuffs is the amount of debuffs on the enemy, a single debuff may give several Buffs.
 type Enemy struct {
-	ID    uint64
-	Name  string
-	Level int
-	Buffs []Buff
+	ID         uint64
+	Name       string
+	Level      int
+	MaxHp      float64
+	HpPct      float64
+	Toughness  float64
+	Weaknesses []Element
+	Broken     bool
+	Debuffs    int
+	Buffs      []Buff
+}
+
+func (e Enemy) IsWeakTo(element Element) bool {
+	if len(e.Weaknesses) == 0 {
+		return true
+	}
+	for _, weakness := range e.Weaknesses {
+		if weakness == element {
+			return true
+		}
+	}
+	return false
+}
+
+// CalcToughnessMultiplier returns the damage multiplier given by the enemy's toughness state:
+// enemies take less damage while their toughness is not broken.
+func CalcToughnessMultiplier(e Enemy) float64 {
+	if e.Broken {
+		return 1.0
+	}
+	return 0.9
+}
+
+// StatValue returns the sum of the enemy's buffs of the given stat that apply to the attack
+func (e Enemy) StatValue(stat Stat, a Attack) float64 {
+	value := 0.0
+	for _, buff := range e.Buffs {
+		if buff.Stat == stat && buff.Element.Is(a.Element) && a.DamageTags.Is(buff.DamageTag) {
+			value += buff.TotalValue()
+		}
+	}
+	return value
+}
+
+func EnemyStats(e Enemy, a Attack) map[Stat]float64 {
+	stats := make(map[Stat]float64)
+
+	for _, stat := range AllEnemyStats() {
+		value := e.StatValue(stat, a)
+		if value != 0 {
+			stats[stat] = value
+		}
+	}
+
+	return stats
 }
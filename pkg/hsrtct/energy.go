@@ -0,0 +1,90 @@
+package hsrtct
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidEnergyCost = errors.New("invalid energy cost")
+var ErrTooManyUltimates = errors.New("only one attack with an energy cost can be derived")
+
+// Base energy gained by killing an enemy
+const EnergyPerKill = 10.0
+
+// EnergyModel is the energy a character gains per rotation outside of its own attacks.
+// EnergyPerHitTaken depends on the enemy's attack, so it has no default value.
+// FlatEnergy is not affected by Energy Regeneration Rate (e.g. energy given by supports).
+type EnergyModel struct {
+	Kills             float64 `json:"kills"`
+	HitsTaken         float64 `json:"hitsTaken"`
+	EnergyPerHitTaken float64 `json:"energyPerHitTaken"`
+	FlatEnergy        float64 `json:"flatEnergy"`
+}
+
+// CalcUltimatesPerRotation calculates how many times the ultimate can be used per rotation.
+// Every other scenario attack gains its EnergyGain times its amount, the ultimate's own EnergyGain is refunded on each use.
+// Every energy gain except FlatEnergy is multiplied by the character's Energy Regeneration Rate.
+func CalcUltimatesPerRotation(s Scenario, ultimate *Attack) (float64, string, error) {
+	if ultimate.EnergyCost <= 0 {
+		return 0, "", fmt.Errorf("%w: %s costs %.2f energy", ErrInvalidEnergyCost, ultimate.Name, ultimate.EnergyCost)
+	}
+	energyRegen := s.Character.FinalStatValue(s.LightCone, s.RelicBuild, EnergyRegenerationRate, DamageTags{NonAttack}, AnyElement, nil) / 100
+
+	attacksEnergy := 0.0
+	for attack, mult := range s.Attacks {
+		if attack != ultimate {
+			attacksEnergy += attack.EnergyGain * mult
+		}
+	}
+	killsEnergy := s.Energy.Kills * EnergyPerKill
+	hitsTakenEnergy := s.Energy.HitsTaken * s.Energy.EnergyPerHitTaken
+	energy := (attacksEnergy+killsEnergy+hitsTakenEnergy)*energyRegen + s.Energy.FlatEnergy
+
+	netCost := ultimate.EnergyCost - ultimate.EnergyGain*energyRegen
+	if netCost <= 0 {
+		return 0, "", fmt.Errorf("%w: %s refunds more energy than it costs", ErrInvalidEnergyCost, ultimate.Name)
+	}
+	ultimates := energy / netCost
+
+	explanation := fmt.Sprintf(
+		"%s uses per rotation: %.2f\n"+
+			"Energy Regeneration Rate: %.2f\n"+
+			"Energy from attacks: %.2f\n"+
+			"Energy from kills: %.2f\n"+
+			"Energy from hits taken: %.2f\n"+
+			"Flat energy: %.2f\n"+
+			"Energy cost: %.2f (%.2f refunded per use)",
+		ultimate.Name, ultimates, energyRegen*100, attacksEnergy*energyRegen, killsEnergy*energyRegen, hitsTakenEnergy*energyRegen, s.Energy.FlatEnergy,
+		ultimate.EnergyCost, ultimate.EnergyGain*energyRegen)
+	return ultimates, explanation, nil
+}
+
+// WithDerivedUltimates returns a copy of the scenario where the amount of the attack with an EnergyCost
+// is derived with CalcUltimatesPerRotation
+func (s Scenario) WithDerivedUltimates() (Scenario, string, error) {
+	var ultimate *Attack
+	for attack := range s.Attacks {
+		if attack.EnergyCost <= 0 {
+			continue
+		}
+		if ultimate != nil {
+			return s, "", ErrTooManyUltimates
+		}
+		ultimate = attack
+	}
+	if ultimate == nil {
+		return s, "", nil
+	}
+
+	ultimates, explanation, err := CalcUltimatesPerRotation(s, ultimate)
+	if err != nil {
+		return s, "", err
+	}
+	attacks := make(map[*Attack]float64, len(s.Attacks))
+	for attack, mult := range s.Attacks {
+		attacks[attack] = mult
+	}
+	attacks[ultimate] = ultimates
+	s.Attacks = attacks
+	return s, explanation, nil
+}
@@ -0,0 +1,77 @@
+package hsrtct_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/j4rv/hsr-tct/pkg/hsrtct"
+)
+
+func getEnergyScenario() (hsrtct.Scenario, *hsrtct.Attack) {
+	basic := hsrtct.Attack{Name: "Basic", ScalingStat: hsrtct.Atk, Multiplier: 100, DamageTags: hsrtct.DamageTags{hsrtct.Basic}, EnergyGain: 20}
+	skill := hsrtct.Attack{Name: "Skill", ScalingStat: hsrtct.Atk, Multiplier: 200, DamageTags: hsrtct.DamageTags{hsrtct.Skill}, EnergyGain: 30}
+	ultimate := hsrtct.Attack{Name: "Ultimate", ScalingStat: hsrtct.Atk, Multiplier: 400, DamageTags: hsrtct.DamageTags{hsrtct.Ultimate}, EnergyCost: 120, EnergyGain: 5}
+	return hsrtct.Scenario{
+		Character:  GetHookCharacter(),
+		LightCone:  GetAeonLC(),
+		RelicBuild: GetHookRelicBuild(),
+		Enemies:    []hsrtct.Enemy{GetBasicEnemy()},
+		Attacks:    map[*hsrtct.Attack]float64{&basic: 1, &skill: 3, &ultimate: 1},
+		Energy:     hsrtct.EnergyModel{Kills: 1, HitsTaken: 2, EnergyPerHitTaken: 5},
+	}, &ultimate
+}
+
+func TestCalcUltimatesPerRotation(t *testing.T) {
+	scn, ultimate := getEnergyScenario()
+	ultimates, _, err := hsrtct.CalcUltimatesPerRotation(scn, ultimate)
+	assertNilError(t, err)
+	// (20 + 3*30 + 10 + 2*5) / (120 - 5)
+	if math.Abs(ultimates-130.0/115) > 1e-9 {
+		t.Fatalf("Expected %v ultimates, got %v", 130.0/115, ultimates)
+	}
+
+	scn.Character.Buffs = append(scn.Character.Buffs, hsrtct.Buff{Stat: hsrtct.EnergyRegenerationRate, Value: 19.4})
+	scn.Energy.FlatEnergy = 10
+	ultimates, _, err = hsrtct.CalcUltimatesPerRotation(scn, ultimate)
+	assertNilError(t, err)
+	expected := (130*1.194 + 10) / (120 - 5*1.194)
+	if math.Abs(ultimates-expected) > 1e-9 {
+		t.Fatalf("Expected %v ultimates, got %v", expected, ultimates)
+	}
+
+	// ERR buffs restricted to some attacks don't change the energy gained
+	scn.Character.Buffs = append(scn.Character.Buffs, hsrtct.Buff{Stat: hsrtct.EnergyRegenerationRate, Value: 50, DamageTag: hsrtct.Skill})
+	ultimates, _, err = hsrtct.CalcUltimatesPerRotation(scn, ultimate)
+	assertNilError(t, err)
+	if math.Abs(ultimates-expected) > 1e-9 {
+		t.Fatalf("Expected %v ultimates, got %v", expected, ultimates)
+	}
+
+	_, _, err = hsrtct.CalcUltimatesPerRotation(scn, &hsrtct.Attack{Name: "Free"})
+	if !errors.Is(err, hsrtct.ErrInvalidEnergyCost) {
+		t.Fatalf("Expected ErrInvalidEnergyCost, got %v", err)
+	}
+}
+
+func TestCalcAvgDmgScenarioDerivedUltimates(t *testing.T) {
+	scn, ultimate := getEnergyScenario()
+	manual := scn
+	manual.Attacks = map[*hsrtct.Attack]float64{}
+	for attack, mult := range scn.Attacks {
+		manual.Attacks[attack] = mult
+	}
+	manual.Attacks[ultimate] = 130.0 / 115
+	expected, err := hsrtct.CalcAvgDmgScenario(manual)
+	assertNilError(t, err)
+
+	scn.DeriveUltimates = true
+	result, err := hsrtct.CalcAvgDmgScenario(scn)
+	assertNilError(t, err)
+	if math.Abs(result.TotalDmg-expected.TotalDmg) > 1e-6 {
+		t.Fatalf("Expected damage to be %v, got %v", expected.TotalDmg, result.TotalDmg)
+	}
+	if scn.Attacks[ultimate] != 1 {
+		t.Fatalf("Expected the scenario attacks to be left untouched, got %v", scn.Attacks[ultimate])
+	}
+}
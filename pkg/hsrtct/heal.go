@@ -0,0 +1,45 @@
+package hsrtct
+
+import "fmt"
+
+// Heal is a healing action that can be used by a character.
+// The healed amount is Multiplier% of ScalingStat plus Flat.
+type Heal struct {
+	ID          uint64
+	Name        string
+	ScalingStat Stat
+	Multiplier  float64
+	Flat        float64
+	Buffs       []Buff
+}
+
+// CalcHeal calculates the amount healed on a target with the given buffs.
+// Only the IncomingHealingBoost buffs of the target are used, and buffs with a DamageTag don't apply to heals.
+func CalcHeal(c Character, lc LightCone, rb RelicBuild, h Heal, targetBuffs []Buff) (float64, string, error) {
+	if err := c.ValidateBuffs(lc, rb, append(append([]Buff{}, h.Buffs...), targetBuffs...)); err != nil {
+		return 0, "", err
+	}
+	statValue, err := scalingStatValue(c, lc, rb, h.ScalingStat, DamageTags{NonAttack}, AnyElement, h.Buffs)
+	if err != nil {
+		return 0, "", err
+	}
+	baseHeal := statValue*h.Multiplier/100 + h.Flat
+	outgoingMult := 1 + c.FinalStatValue(lc, rb, OutgoingHealingBoost, DamageTags{NonAttack}, AnyElement, h.Buffs)/100
+
+	incomingHealingBoost := 0.0
+	for _, buff := range targetBuffs {
+		if buff.Stat == IncomingHealingBoost {
+			incomingHealingBoost += buff.TotalValue()
+		}
+	}
+	incomingMult := 1 + incomingHealingBoost/100
+
+	explanation := fmt.Sprintf(
+		"Base Healing: %.2f\n"+
+			"%s: %.2f\n"+
+			"Outgoing Healing Multiplier: %.2f\n"+
+			"Incoming Healing Multiplier: %.2f",
+		baseHeal, h.ScalingStat, statValue, outgoingMult, incomingMult)
+
+	return baseHeal * outgoingMult * incomingMult, explanation, nil
+}
@@ -0,0 +1,71 @@
+package hsrtct_test
+
+import (
+	"testing"
+
+	"github.com/j4rv/hsr-tct/pkg/hsrtct"
+)
+
+func GetLuochaCharacter() hsrtct.Character {
+	return hsrtct.Character{
+		Name:    "Luocha",
+		Level:   80,
+		BaseHp:  1280,
+		BaseAtk: 756,
+		BaseDef: 363,
+		BaseSpd: 101,
+		Element: hsrtct.Imaginary,
+		Buffs: []hsrtct.Buff{
+			{Stat: hsrtct.AtkPct, Value: 28},
+			{Stat: hsrtct.OutgoingHealingBoost, Value: 35},
+		},
+	}
+}
+
+func TestCalcHeal(t *testing.T) {
+	luocha := GetLuochaCharacter()
+	skill := hsrtct.Heal{
+		Name:        "Prayer of Abyss Flower",
+		ScalingStat: hsrtct.Atk,
+		Multiplier:  60,
+		Flat:        800,
+	}
+	targetBuffs := []hsrtct.Buff{{Stat: hsrtct.IncomingHealingBoost, Value: 10}}
+
+	healing, _, err := hsrtct.CalcHeal(luocha, hsrtct.LightCone{}, hsrtct.RelicBuild{}, skill, targetBuffs)
+	assertNilError(t, err)
+	// (756 * 1.28 * 0.6 + 800) * 1.35 * 1.1
+	if int(healing) != 2050 {
+		t.Fatalf("Expected healing to be 2050, got %v", healing)
+	}
+
+	scn := hsrtct.Scenario{
+		Character:       luocha,
+		Heals:           map[*hsrtct.Heal]float64{&skill: 2},
+		HealTargetBuffs: targetBuffs,
+	}
+	scnResult, err := hsrtct.CalcAvgDmgScenario(scn)
+	assertNilError(t, err)
+	if int(scnResult.TotalHeal) != 4100 {
+		t.Fatalf("Expected total healing to be 4100, got %v", scnResult.TotalHeal)
+	}
+}
+
+func TestCalcHealIgnoresTaggedBuffs(t *testing.T) {
+	luocha := GetLuochaCharacter()
+	skill := hsrtct.Heal{
+		Name:        "Prayer of Abyss Flower",
+		ScalingStat: hsrtct.Atk,
+		Multiplier:  60,
+		Flat:        800,
+	}
+	expected, _, err := hsrtct.CalcHeal(luocha, hsrtct.LightCone{}, hsrtct.RelicBuild{}, skill, nil)
+	assertNilError(t, err)
+
+	luocha.Buffs = append(luocha.Buffs, hsrtct.Buff{Stat: hsrtct.AtkPct, Value: 50, DamageTag: hsrtct.Ultimate})
+	healing, _, err := hsrtct.CalcHeal(luocha, hsrtct.LightCone{}, hsrtct.RelicBuild{}, skill, nil)
+	assertNilError(t, err)
+	if healing != expected {
+		t.Fatalf("Expected Ultimate buffs not to affect healing, expected %v, got %v", expected, healing)
+	}
+}
@@ -0,0 +1,35 @@
+package hsrtct
+
+import "fmt"
+
+// Shield is a shielding action that can be used by a character.
+// The shield strength is Multiplier% of ScalingStat plus Flat.
+type Shield struct {
+	ID          uint64
+	Name        string
+	ScalingStat Stat
+	Multiplier  float64
+	Flat        float64
+	Buffs       []Buff
+}
+
+// CalcShield calculates the strength of the shield, buffs with a DamageTag don't apply to shields
+func CalcShield(c Character, lc LightCone, rb RelicBuild, s Shield) (float64, string, error) {
+	if err := c.ValidateBuffs(lc, rb, s.Buffs); err != nil {
+		return 0, "", err
+	}
+	statValue, err := scalingStatValue(c, lc, rb, s.ScalingStat, DamageTags{NonAttack}, AnyElement, s.Buffs)
+	if err != nil {
+		return 0, "", err
+	}
+	baseShield := statValue*s.Multiplier/100 + s.Flat
+	shieldBonusMult := 1 + c.FinalStatValue(lc, rb, ShieldBonus, DamageTags{NonAttack}, AnyElement, s.Buffs)/100
+
+	explanation := fmt.Sprintf(
+		"Base Shield: %.2f\n"+
+			"%s: %.2f\n"+
+			"Shield Bonus Multiplier: %.2f",
+		baseShield, s.ScalingStat, statValue, shieldBonusMult)
+
+	return baseShield * shieldBonusMult, explanation, nil
+}
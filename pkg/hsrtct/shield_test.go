@@ -0,0 +1,67 @@
+package hsrtct_test
+
+import (
+	"testing"
+
+	"github.com/j4rv/hsr-tct/pkg/hsrtct"
+)
+
+func TestCalcShield(t *testing.T) {
+	gepard := hsrtct.Character{
+		Name:    "Gepard",
+		Level:   80,
+		BaseHp:  1397,
+		BaseAtk: 543,
+		BaseDef: 654,
+		Buffs: []hsrtct.Buff{
+			{Stat: hsrtct.DefPct, Value: 35},
+			{Stat: hsrtct.ShieldBonus, Value: 20},
+		},
+	}
+	lc := hsrtct.LightCone{BaseDef: 463}
+	ultimate := hsrtct.Shield{
+		Name:        "Enduring Bulwark",
+		ScalingStat: hsrtct.Def,
+		Multiplier:  45,
+		Flat:        600,
+	}
+
+	scn := hsrtct.Scenario{
+		Character: gepard,
+		LightCone: lc,
+		Shields:   map[*hsrtct.Shield]float64{&ultimate: 2},
+	}
+	scnResult, err := hsrtct.CalcAvgDmgScenario(scn)
+	assertNilError(t, err)
+	// ((654 + 463) * 1.35 * 0.45 + 600) * 1.2
+	if int(scnResult.ShieldPerAction[&ultimate]) != 1534 {
+		t.Fatalf("Expected shield to be 1534, got %v", scnResult.ShieldPerAction[&ultimate])
+	}
+	if int(scnResult.TotalShield) != 3068 {
+		t.Fatalf("Expected total shield to be 3068, got %v", scnResult.TotalShield)
+	}
+}
+
+func TestCalcShieldPerAction(t *testing.T) {
+	gepard := hsrtct.Character{
+		Name:    "Gepard",
+		Level:   80,
+		BaseDef: 654,
+		Buffs: []hsrtct.Buff{
+			{Stat: hsrtct.DefPct, Value: 50, DamageTag: hsrtct.Ultimate},
+		},
+	}
+	small := hsrtct.Shield{ScalingStat: hsrtct.Def, Multiplier: 10}
+	big := hsrtct.Shield{ScalingStat: hsrtct.Def, Multiplier: 20}
+
+	scn := hsrtct.Scenario{
+		Character: gepard,
+		Shields:   map[*hsrtct.Shield]float64{&small: 1, &big: 1},
+	}
+	scnResult, err := hsrtct.CalcAvgDmgScenario(scn)
+	assertNilError(t, err)
+	// Unnamed shields don't overwrite each other, and Ultimate buffs don't apply
+	if scnResult.ShieldPerAction[&small] != 65.4 || scnResult.ShieldPerAction[&big] != 130.8 {
+		t.Fatalf("Expected shields to be 65.4 and 130.8, got %v", scnResult.ShieldPerAction)
+	}
+}
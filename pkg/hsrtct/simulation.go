@@ -0,0 +1,166 @@
+package hsrtct
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+var ErrInvalidRotations = errors.New("invalid amount of rotations")
+
+// SimulationConfig configures a Monte Carlo simulation.
+// Rotations is the amount of times the scenario is simulated, the same Seed always gives the same results.
+type SimulationConfig struct {
+	Rotations int
+	Seed      int64
+}
+
+// SimulationResult summarizes the total damage of every simulated rotation
+type SimulationResult struct {
+	Rotations int
+	Mean      float64
+	Min       float64
+	Max       float64
+	P5        float64
+	P50       float64
+	P95       float64
+}
+
+// SimulateScenario simulates the scenario cfg.Rotations times, rolling crits for every hit.
+// Fractional attack amounts (e.g. 2.5 ultimates) are rolled as an extra use with that probability.
+// Every use of a Bounce attack lands its Hits hits, rolling the target of each one.
+// Buffs with partial uptime are still weighted, only crits are rolled.
+func SimulateScenario(s Scenario, cfg SimulationConfig) (SimulationResult, error) {
+	if cfg.Rotations <= 0 {
+		return SimulationResult{}, ErrInvalidRotations
+	}
+	result, err := CalcAvgDmgScenario(s)
+	if err != nil {
+		return SimulationResult{}, err
+	}
+
+	// Scenario attacks are a map, sorting the hits keeps the rolls in the same order for a given seed
+	hits := append([]scenarioHit{}, result.hits...)
+	sort.Slice(hits, func(i, j int) bool {
+		a, b := hits[i], hits[j]
+		if a.dist != b.dist {
+			return lessDistribution(a.dist, b.dist)
+		}
+		if a.rolls != b.rolls {
+			return a.rolls < b.rolls
+		}
+		if a.scale != b.scale {
+			return a.scale < b.scale
+		}
+		if a.bounces != b.bounces {
+			return a.bounces < b.bounces
+		}
+		if a.focused != b.focused {
+			return a.focused < b.focused
+		}
+		if a.firstHitFocused != b.firstHitFocused {
+			return b.firstHitFocused
+		}
+		for k := 0; k < len(a.targets) && k < len(b.targets); k++ {
+			if a.targets[k] != b.targets[k] {
+				return lessDistribution(a.targets[k], b.targets[k])
+			}
+		}
+		return len(a.targets) < len(b.targets)
+	})
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	totals := make([]float64, cfg.Rotations)
+	for i := range totals {
+		for _, hit := range hits {
+			rolls := int(hit.rolls)
+			if rng.Float64() < hit.rolls-math.Floor(hit.rolls) {
+				rolls++
+			}
+			for r := 0; r < rolls; r++ {
+				if hit.bounces == 0 {
+					totals[i] += rollCrit(rng, hit.dist) * hit.scale
+					continue
+				}
+				for b := 0; b < hit.bounces; b++ {
+					target := hit.focused
+					if b > 0 || !hit.firstHitFocused {
+						target = rng.Intn(len(hit.targets))
+					}
+					totals[i] += rollCrit(rng, hit.targets[target]) * hit.scale
+				}
+			}
+		}
+	}
+
+	sort.Float64s(totals)
+	sum := 0.0
+	for _, total := range totals {
+		sum += total
+	}
+	return SimulationResult{
+		Rotations: cfg.Rotations,
+		Mean:      sum / float64(cfg.Rotations),
+		Min:       totals[0],
+		Max:       totals[len(totals)-1],
+		P5:        percentile(totals, 5),
+		P50:       percentile(totals, 50),
+		P95:       percentile(totals, 95),
+	}, nil
+}
+
+// rollCrit returns the damage of a single hit, rolling whether it crits
+func rollCrit(rng *rand.Rand, dist DamageDistribution) float64 {
+	if dist.CritRate > 0 && rng.Float64()*100 < dist.CritRate {
+		return dist.Crit
+	}
+	return dist.NonCrit
+}
+
+// lessDistribution orders damage distributions by their fields
+func lessDistribution(a, b DamageDistribution) bool {
+	if a.NonCrit != b.NonCrit {
+		return a.NonCrit < b.NonCrit
+	}
+	if a.Crit != b.Crit {
+		return a.Crit < b.Crit
+	}
+	if a.CritRate != b.CritRate {
+		return a.CritRate < b.CritRate
+	}
+	if a.Expected != b.Expected {
+		return a.Expected < b.Expected
+	}
+	return a.Variance < b.Variance
+}
+
+// SimulateScenarios simulates every scenario concurrently.
+// Each scenario uses cfg.Seed plus its index as seed, so results don't depend on scheduling.
+// The returned slices are indexed like scenarios, errors are nil for scenarios that were simulated.
+func SimulateScenarios(scenarios []Scenario, cfg SimulationConfig) ([]SimulationResult, []error) {
+	results := make([]SimulationResult, len(scenarios))
+	errs := make([]error, len(scenarios))
+	var wg sync.WaitGroup
+	for i, scenario := range scenarios {
+		wg.Add(1)
+		go func(i int, scenario Scenario) {
+			defer wg.Done()
+			scenarioCfg := cfg
+			scenarioCfg.Seed += int64(i)
+			results[i], errs[i] = SimulateScenario(scenario, scenarioCfg)
+		}(i, scenario)
+	}
+	wg.Wait()
+	return results, errs
+}
+
+// percentile returns the nearest-rank percentile p of the sorted values
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,106 @@
+package hsrtct_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/j4rv/hsr-tct/pkg/hsrtct"
+)
+
+func getSimulationScenario() hsrtct.Scenario {
+	skill := hsrtct.Attack{
+		ScalingStat: hsrtct.Atk,
+		Multiplier:  100,
+		Element:     hsrtct.Fire,
+		DamageTags:  hsrtct.DamageTags{hsrtct.Skill},
+		AttackAOE:   hsrtct.Blast,
+	}
+	ultimate := hsrtct.Attack{
+		ScalingStat: hsrtct.Atk,
+		Multiplier:  400,
+		Element:     hsrtct.Fire,
+		DamageTags:  hsrtct.DamageTags{hsrtct.Ultimate},
+	}
+	return hsrtct.Scenario{
+		Character:    GetHookCharacter(),
+		LightCone:    GetAeonLC(),
+		RelicBuild:   GetHookRelicBuild(),
+		Enemies:      []hsrtct.Enemy{GetBasicEnemy(), GetBasicEnemy(), GetBasicEnemy()},
+		FocusedEnemy: 1,
+		Attacks:      map[*hsrtct.Attack]float64{&skill: 3, &ultimate: 1.5},
+	}
+}
+
+func TestSimulateScenario(t *testing.T) {
+	scn := getSimulationScenario()
+	expected, err := hsrtct.CalcAvgDmgScenario(scn)
+	assertNilError(t, err)
+
+	cfg := hsrtct.SimulationConfig{Rotations: 20000, Seed: 42}
+	result, err := hsrtct.SimulateScenario(scn, cfg)
+	assertNilError(t, err)
+
+	if math.Abs(result.Mean-expected.TotalDmg)/expected.TotalDmg > 0.01 {
+		t.Fatalf("Expected mean damage to be close to %v, got %v", expected.TotalDmg, result.Mean)
+	}
+	if !(result.Min <= result.P5 && result.P5 <= result.P50 && result.P50 <= result.P95 && result.P95 <= result.Max) {
+		t.Fatalf("Expected ordered percentiles, got %+v", result)
+	}
+
+	again, err := hsrtct.SimulateScenario(getSimulationScenario(), cfg)
+	assertNilError(t, err)
+	if again != result {
+		t.Fatalf("Expected the same seed to give the same results, got %+v and %+v", result, again)
+	}
+}
+
+func TestSimulateScenarios(t *testing.T) {
+	scenarios := []hsrtct.Scenario{getSimulationScenario(), getSimulationScenario(), getSimulationScenario()}
+	cfg := hsrtct.SimulationConfig{Rotations: 1000, Seed: 7}
+	results, errs := hsrtct.SimulateScenarios(scenarios, cfg)
+
+	for i, scenario := range scenarios {
+		assertNilError(t, errs[i])
+		scenarioCfg := cfg
+		scenarioCfg.Seed += int64(i)
+		expected, err := hsrtct.SimulateScenario(scenario, scenarioCfg)
+		assertNilError(t, err)
+		if results[i] != expected {
+			t.Fatalf("Expected scenario %d to be simulated with seed %d, got %+v instead of %+v", i, scenarioCfg.Seed, results[i], expected)
+		}
+	}
+}
+
+func TestSimulateScenarioInvalidRotations(t *testing.T) {
+	_, err := hsrtct.SimulateScenario(getSimulationScenario(), hsrtct.SimulationConfig{})
+	if !errors.Is(err, hsrtct.ErrInvalidRotations) {
+		t.Fatalf("Expected ErrInvalidRotations, got %v", err)
+	}
+}
+
+func TestSimulateScenarioBounceHits(t *testing.T) {
+	bounce := hsrtct.Attack{
+		ScalingStat: hsrtct.Atk,
+		Multiplier:  100,
+		Element:     hsrtct.Fire,
+		DamageTags:  hsrtct.DamageTags{hsrtct.Skill},
+		AttackAOE:   hsrtct.Bounce,
+		Hits:        4,
+		Buffs:       []hsrtct.Buff{{Stat: hsrtct.CritRate, Value: -100}},
+	}
+	scn := getSimulationScenario()
+	scn.Attacks = map[*hsrtct.Attack]float64{&bounce: 1}
+	expected, err := hsrtct.CalcAvgDmgScenario(scn)
+	assertNilError(t, err)
+
+	// Without crits, every rotation lands the same 4 hits on identical enemies
+	for _, firstHitFocused := range []bool{false, true} {
+		bounce.FirstHitFocused = firstHitFocused
+		result, err := hsrtct.SimulateScenario(scn, hsrtct.SimulationConfig{Rotations: 1000, Seed: 42})
+		assertNilError(t, err)
+		if math.Abs(result.Min-expected.TotalDmg) > 1e-6 || math.Abs(result.Max-expected.TotalDmg) > 1e-6 {
+			t.Fatalf("Expected every rotation to deal %v damage, got %+v", expected.TotalDmg, result)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package hsrtct
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrInvalidSpGain = errors.New("invalid skill point gain")
+
+// The team's skill point pool starts at StartingSkillPoints and can't exceed MaxSkillPoints
+const StartingSkillPoints = 3
+const MaxSkillPoints = 5
+
+// SpDeficit is an action that couldn't be used because the team didn't have enough skill points
+type SpDeficit struct {
+	Index   int
+	Action  string
+	Missing int
+}
+
+// SpReport is the result of tracking the skill points of a sequence of actions.
+// Wasted is the amount of skill points generated while the pool was full.
+type SpReport struct {
+	Final    int
+	Wasted   int
+	Deficits []SpDeficit
+}
+
+func (r SpReport) IsValid() bool {
+	return len(r.Deficits) == 0
+}
+
+func (r SpReport) String() string {
+	result := fmt.Sprintf("Final SP: %d, wasted SP: %d", r.Final, r.Wasted)
+	for _, deficit := range r.Deficits {
+		result += fmt.Sprintf("\n%s (action %d) is missing %d SP", deficit.Action, deficit.Index+1, deficit.Missing)
+	}
+	return result
+}
+
+// TrackSkillPoints follows the team's skill point pool across the actions, in order.
+// Actions without enough skill points are reported as deficits and leave the pool empty.
+func TrackSkillPoints(actions []Attack, startingSp int) SpReport {
+	report := SpReport{}
+	sp := startingSp
+	for i, action := range actions {
+		sp -= action.SpCost
+		if sp < 0 {
+			report.Deficits = append(report.Deficits, SpDeficit{Index: i, Action: action.Name, Missing: -sp})
+			sp = 0
+		}
+		sp += action.SpGain
+		if sp > MaxSkillPoints {
+			report.Wasted += sp - MaxSkillPoints
+			sp = MaxSkillPoints
+		}
+	}
+	report.Final = sp
+	return report
+}
+
+// CalcSpBalance returns the skill points generated minus the skill points spent per rotation by the scenario
+func CalcSpBalance(s Scenario) float64 {
+	balance := s.TeamSpBalance
+	for attack, mult := range s.Attacks {
+		balance += float64(attack.SpGain-attack.SpCost) * mult
+	}
+	return balance
+}
+
+// SuggestBasics returns how many more uses of basic per rotation the scenario needs to sustain its skill point spending
+func SuggestBasics(s Scenario, basic Attack) (float64, error) {
+	basicGain := basic.SpGain - basic.SpCost
+	if basicGain <= 0 {
+		return 0, fmt.Errorf("%w: %s generates %d SP", ErrInvalidSpGain, basic.Name, basicGain)
+	}
+	return math.Max(-CalcSpBalance(s), 0) / float64(basicGain), nil
+}
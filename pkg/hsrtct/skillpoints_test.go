@@ -0,0 +1,53 @@
+package hsrtct_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/j4rv/hsr-tct/pkg/hsrtct"
+)
+
+func TestTrackSkillPoints(t *testing.T) {
+	basic := hsrtct.Attack{Name: "Basic", SpGain: 1}
+	skill := hsrtct.Attack{Name: "Skill", SpCost: 1}
+	actions := []hsrtct.Attack{skill, skill, skill, skill, basic, skill}
+
+	report := hsrtct.TrackSkillPoints(actions, hsrtct.StartingSkillPoints)
+	if report.IsValid() || len(report.Deficits) != 1 {
+		t.Fatalf("Expected one deficit, got %+v", report)
+	}
+	if deficit := report.Deficits[0]; deficit.Index != 3 || deficit.Action != "Skill" || deficit.Missing != 1 {
+		t.Fatalf("Expected the 4th skill to miss 1 SP, got %+v", deficit)
+	}
+	if report.Final != 0 {
+		t.Fatalf("Expected 0 SP left, got %d", report.Final)
+	}
+
+	report = hsrtct.TrackSkillPoints([]hsrtct.Attack{basic, basic, basic, skill}, hsrtct.StartingSkillPoints)
+	if !report.IsValid() || report.Wasted != 1 || report.Final != 4 {
+		t.Fatalf("Expected a valid rotation wasting 1 SP and ending with 4, got %+v", report)
+	}
+}
+
+func TestSuggestBasics(t *testing.T) {
+	basic := hsrtct.Attack{Name: "Basic", SpGain: 1}
+	skill := hsrtct.Attack{Name: "Skill", SpCost: 1}
+	scn := hsrtct.Scenario{
+		Attacks:       map[*hsrtct.Attack]float64{&skill: 4, &basic: 1},
+		TeamSpBalance: 1,
+	}
+
+	if balance := hsrtct.CalcSpBalance(scn); balance != -2 {
+		t.Fatalf("Expected a balance of -2 SP, got %v", balance)
+	}
+	basics, err := hsrtct.SuggestBasics(scn, basic)
+	assertNilError(t, err)
+	if basics != 2 {
+		t.Fatalf("Expected 2 more basics, got %v", basics)
+	}
+
+	_, err = hsrtct.SuggestBasics(scn, skill)
+	if !errors.Is(err, hsrtct.ErrInvalidSpGain) {
+		t.Fatalf("Expected ErrInvalidSpGain, got %v", err)
+	}
+}
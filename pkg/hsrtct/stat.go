@@ -1,10 +1,15 @@
 package hsrtct
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"strings"
 )
 
+var ErrInvalidStacks = errors.New("invalid stacks")
+var ErrInvalidUptime = errors.New("invalid uptime")
+
 type Stat string
 
 const (
@@ -19,10 +24,14 @@ const (
 	CritRate               Stat = "CritRate"
 	CritDmg                Stat = "CritDmg"
 	OutgoingHealingBoost   Stat = "OutgoingHealingBoost"
+	IncomingHealingBoost   Stat = "IncomingHealingBoost"
+	ShieldBonus            Stat = "ShieldBonus"
 	EffectHitRate          Stat = "EffectHitRate"
 	EffectRes              Stat = "EffectRes"
 	EnergyRegenerationRate Stat = "EnergyRegenerationRate"
 	BreakEffect            Stat = "BreakEffect"
+	BreakDmgBonus          Stat = "BreakDmgBonus"
+	SuperBreakDmg          Stat = "SuperBreakDmg"
 	DefIgnore              Stat = "DefIgnore"
 	DefShred               Stat = "DefShred"
 	Aggro                  Stat = "Aggro"
@@ -32,23 +41,108 @@ const (
 	ResPen                 Stat = "ResPen"
 	Vulnerability          Stat = "Vulnerability"
 	DmgReduction           Stat = "DmgReduction"
+	CritRateTaken          Stat = "CritRateTaken"
+	CritDmgTaken           Stat = "CritDmgTaken"
+	EnemyMaxHp             Stat = "EnemyMaxHp" // Only valid as an attack's ScalingStat
 )
 
 func AllStats() []Stat {
 	return []Stat{
 		Hp, Atk, Def, Spd,
 		HpPct, AtkPct, DefPct, SpdPct,
-		CritRate, CritDmg, OutgoingHealingBoost, EffectHitRate, EffectRes,
-		EnergyRegenerationRate, BreakEffect, DefIgnore, DefShred, Aggro,
+		CritRate, CritDmg, OutgoingHealingBoost, ShieldBonus, EffectHitRate, EffectRes,
+		EnergyRegenerationRate, BreakEffect, BreakDmgBonus, SuperBreakDmg,
+		DefIgnore, DefShred, Aggro,
 		DmgBonus, ElementalRes, ResShred, ResPen, Vulnerability,
 	}
 }
 
+// AllEnemyStats returns the stats that are read from the enemy's buffs
+func AllEnemyStats() []Stat {
+	return []Stat{
+		ElementalRes, ResShred, DefShred, Vulnerability, DmgReduction,
+		CritRateTaken, CritDmgTaken,
+	}
+}
+
+// Buff increases (or decreases) a Stat by Value.
+// Stacking buffs also add ValuePerStack for each of their Stacks, which can't be more than MaxStacks (if MaxStacks is not zero).
+// Name is optional, and is used to find the buff when overriding its stacks.
+// Uptime is the fraction of the time the buff is active, zero means it's always active.
+// Conditional buffs only apply to hits that meet their Condition, see Condition. Stats calculated outside of a hit
+// (heals, shields, buff sources, timelines or Character.FinalStatValue on its own) leave them out.
+// If SourceStat is set, the buff is a conversion instead: its value is Ratio% of the SourceStat above Threshold,
+// up to Cap (if Cap is not zero). Conversions never use other conversions to calculate their SourceStat.
+// If Source is set, the SourceStat of the conversion is the one of the Source character instead (e.g. a support's CRIT DMG).
 type Buff struct {
-	Stat      Stat      `json:"stat"`
-	Value     float64   `json:"value"`
-	DamageTag DamageTag `json:"damageTag"`
-	Element   Element   `json:"element"`
+	Name           string      `json:"name"`
+	Stat           Stat        `json:"stat"`
+	Value          float64     `json:"value"`
+	ValuePerStack  float64     `json:"valuePerStack"`
+	Stacks         int         `json:"stacks"`
+	MaxStacks      int         `json:"maxStacks"`
+	Uptime         float64     `json:"uptime"`
+	Condition      Condition   `json:"condition"`
+	ConditionValue float64     `json:"conditionValue"`
+	DamageTag      DamageTag   `json:"damageTag"`
+	Element        Element     `json:"element"`
+	SourceStat     Stat        `json:"sourceStat"`
+	Ratio          float64     `json:"ratio"`
+	Threshold      float64     `json:"threshold"`
+	Cap            float64     `json:"cap"`
+	Source         *BuffSource `json:"source,omitempty"`
+}
+
+// TotalValue returns the value of the buff including its stacks.
+// Buffs with partial uptime are scaled linearly by their uptime.
+func (b Buff) TotalValue() float64 {
+	value := b.Value + b.ValuePerStack*float64(b.Stacks)
+	if b.HasPartialUptime() {
+		value *= b.Uptime
+	}
+	return value
+}
+
+func (b Buff) HasPartialUptime() bool {
+	return b.Uptime > 0 && b.Uptime < 1
+}
+
+func (b Buff) Validate() error {
+	if b.Stacks < 0 || b.MaxStacks > 0 && b.Stacks > b.MaxStacks {
+		return fmt.Errorf("%w: %s has %d stacks (max %d)", ErrInvalidStacks, b, b.Stacks, b.MaxStacks)
+	}
+	if b.Uptime < 0 || b.Uptime > 1 {
+		return fmt.Errorf("%w: %s has %.2f uptime (must be between 0 and 1)", ErrInvalidUptime, b, b.Uptime)
+	}
+	return nil
+}
+
+// withStacks returns a copy of the buffs, with the stacks of the named buffs replaced
+func withStacks(buffs []Buff, stacks map[string]int) []Buff {
+	if len(stacks) == 0 {
+		return buffs
+	}
+	result := make([]Buff, len(buffs))
+	for i, buff := range buffs {
+		if s, ok := stacks[buff.Name]; ok && buff.Name != "" {
+			buff.Stacks = s
+		}
+		result[i] = buff
+	}
+	return result
+}
+
+func (b Buff) IsConversion() bool {
+	return b.SourceStat != ""
+}
+
+// ConvertedValue returns the value of a conversion buff given the value of its SourceStat
+func (b Buff) ConvertedValue(sourceValue float64) float64 {
+	value := math.Max(sourceValue-b.Threshold, 0) * b.Ratio / 100
+	if b.Cap != 0 {
+		value = math.Min(value, b.Cap)
+	}
+	return value
 }
 
 func (b Buff) String() string {
@@ -57,7 +151,19 @@ func (b Buff) String() string {
 	if !(b.Stat == Hp || b.Stat == Atk || b.Stat == Def || b.Stat == Spd || b.Stat == Aggro) {
 		valueSuffix = "%"
 	}
-	result := fmt.Sprintf("%.1f%s %s", b.Value, valueSuffix, prettyStat)
+	result := fmt.Sprintf("%.1f%s %s", b.TotalValue(), valueSuffix, prettyStat)
+
+	if b.ValuePerStack != 0 {
+		result += fmt.Sprintf("(%d stacks)", b.Stacks)
+	}
+
+	if b.HasPartialUptime() {
+		result += fmt.Sprintf("(%.0f%% uptime)", b.Uptime*100)
+	}
+
+	if b.Condition != Always {
+		result += fmt.Sprintf("(%s)", b.Condition)
+	}
 
 	if b.DamageTag != "" {
 		result += fmt.Sprintf("(%s)", b.DamageTag)
@@ -67,5 +173,18 @@ func (b Buff) String() string {
 		result += fmt.Sprintf("(%s)", b.Element)
 	}
 
+	if b.IsConversion() {
+		result += fmt.Sprintf(" from %.1f%% of %s", b.Ratio, b.SourceStat)
+		if b.Source != nil {
+			result += fmt.Sprintf(" of %s", b.Source.Character.Name)
+		}
+		if b.Threshold != 0 {
+			result += fmt.Sprintf(" above %.1f", b.Threshold)
+		}
+		if b.Cap != 0 {
+			result += fmt.Sprintf(" (max %.1f)", b.Cap)
+		}
+	}
+
 	return result
 }
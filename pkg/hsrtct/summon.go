@@ -0,0 +1,83 @@
+package hsrtct
+
+import "fmt"
+
+// Summon is an entity of a character that acts on its own (e.g. Topaz's Numby or Jing Yuan's Lightning-Lord).
+// Its attacks scale off the owner's stats, and only use the owner's buffs if InheritBuffs is true,
+// otherwise only the owner's base stats, light cone base stats and relic stats are used.
+// Buffs only apply to the summon. Stacks are the stacks of the named buffs while the summon attacks,
+// like a Scenario's StackOverrides for every attack of the summon.
+// Spd is the summon's own speed, used to simulate its turns (see Summon.TimelineUnit).
+// If RotationAV is positive, Attacks are the attacks of each of the summon's turns, and the summon gets
+// as many turns per rotation as its Spd allows in RotationAV. Otherwise Attacks are the amounts per rotation,
+// given by the caller (e.g. from a SimulateTimeline result).
+type Summon struct {
+	Name         string
+	Spd          float64
+	RotationAV   float64
+	Attacks      map[*Attack]float64
+	Stacks       map[string]int
+	InheritBuffs bool
+	Buffs        []Buff
+}
+
+// TimelineUnit returns the summon as a unit of a Timeline, acting with the given actions
+func (s Summon) TimelineUnit(actions []DamageTag) TimelineUnit {
+	return TimelineUnit{Name: s.Name, BaseSpd: s.Spd, Spd: s.Spd, Actions: actions}
+}
+
+// Turns returns the summon's turns per rotation, or 1 if its attacks are already amounts per rotation
+func (s Summon) Turns() (float64, error) {
+	if s.RotationAV <= 0 {
+		return 1, nil
+	}
+	if s.Spd <= 0 {
+		return 0, fmt.Errorf("%w: %s has %.2f speed", ErrInvalidSpd, s.Name, s.Spd)
+	}
+	return s.RotationAV / ActionValue(s.Spd), nil
+}
+
+// summonScenario returns the scenario of the summon attacking the scenario's enemies.
+// The scenario's StackOverrides are keyed by the owner's attacks, so the summon uses its own Stacks instead.
+// Summon attacks don't give energy nor skill points to the owner, so Energy, DeriveUltimates and TeamSpBalance are not used.
+func (s Scenario) summonScenario(summon Summon) (Scenario, error) {
+	turns, err := summon.Turns()
+	if err != nil {
+		return Scenario{}, err
+	}
+	attacks := make(map[*Attack]float64, len(summon.Attacks))
+	for attack, mult := range summon.Attacks {
+		attacks[attack] = mult * turns
+	}
+
+	character := s.Character
+	lc := s.LightCone
+	rb := s.RelicBuild
+	if !summon.InheritBuffs {
+		character.Buffs = nil
+		lc.Buffs = nil
+		rb.SetEffects = nil
+	}
+	buffs := make([]Buff, 0, len(character.Buffs)+len(summon.Buffs))
+	buffs = append(buffs, character.Buffs...)
+	character.Buffs = append(buffs, summon.Buffs...)
+	character.Name = summon.Name
+
+	stackOverrides := make(map[*Attack]map[string]int, len(summon.Attacks))
+	for attack := range summon.Attacks {
+		stackOverrides[attack] = summon.Stacks
+	}
+
+	return Scenario{
+		ID:             s.ID,
+		Name:           s.Name + " - " + summon.Name,
+		Character:      character,
+		LightCone:      lc,
+		RelicBuild:     rb,
+		Enemies:        s.Enemies,
+		FocusedEnemy:   s.FocusedEnemy,
+		Attacks:        attacks,
+		StackOverrides: stackOverrides,
+		UptimeMode:     s.UptimeMode,
+	}, nil
+}
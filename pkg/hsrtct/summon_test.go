@@ -0,0 +1,104 @@
+package hsrtct_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/j4rv/hsr-tct/pkg/hsrtct"
+)
+
+func TestCalcAvgDmgScenarioSummons(t *testing.T) {
+	hook := GetHookCharacter()
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	skill := hsrtct.Attack{Name: "Skill", ScalingStat: hsrtct.Atk, Multiplier: 200, DamageTags: hsrtct.DamageTags{hsrtct.Skill}}
+	numbyAttack := hsrtct.Attack{Name: "Numby", ScalingStat: hsrtct.Atk, Multiplier: 150, DamageTags: hsrtct.DamageTags{hsrtct.FollowUp}}
+	numbyBuff := hsrtct.Buff{Name: "Windfall", Stat: hsrtct.CritDmg, ValuePerStack: 25, MaxStacks: 2}
+	numby := hsrtct.Summon{
+		Name:         "Numby",
+		Spd:          80,
+		Attacks:      map[*hsrtct.Attack]float64{&numbyAttack: 4},
+		Stacks:       map[string]int{"Windfall": 2},
+		InheritBuffs: true,
+		Buffs:        []hsrtct.Buff{numbyBuff},
+	}
+
+	scn := hsrtct.Scenario{
+		Character:  hook,
+		LightCone:  lc,
+		RelicBuild: rb,
+		Enemies:    []hsrtct.Enemy{GetBasicEnemy()},
+		Attacks:    map[*hsrtct.Attack]float64{&skill: 2},
+		Summons:    []hsrtct.Summon{numby},
+	}
+	result, err := hsrtct.CalcAvgDmgScenario(scn)
+	assertNilError(t, err)
+
+	skillDmg, _, err := hsrtct.CalcAvgDamage(hook, lc, rb, GetBasicEnemy(), skill, false)
+	assertNilError(t, err)
+	buffedHook := GetHookCharacter()
+	buffedHook.Buffs = append(buffedHook.Buffs, hsrtct.Buff{Stat: hsrtct.CritDmg, Value: 50})
+	numbyDmg, _, err := hsrtct.CalcAvgDamage(buffedHook, lc, rb, GetBasicEnemy(), numbyAttack, false)
+	assertNilError(t, err)
+
+	if math.Abs(result.SummonDmg["Numby"]-numbyDmg*4) > 1e-6 {
+		t.Fatalf("Expected Numby damage to be %v, got %v", numbyDmg*4, result.SummonDmg["Numby"])
+	}
+	if math.Abs(result.TotalDmg-numbyDmg*4-skillDmg*2) > 1e-6 {
+		t.Fatalf("Expected total damage to be %v, got %v", numbyDmg*4+skillDmg*2, result.TotalDmg)
+	}
+
+	// Without inheriting, the light cone and the character buffs are not used
+	scn.Summons[0].InheritBuffs = false
+	scn.Summons[0].Buffs = nil
+	result, err = hsrtct.CalcAvgDmgScenario(scn)
+	assertNilError(t, err)
+	unbuffedHook := GetHookCharacter()
+	unbuffedHook.Buffs = nil
+	unbuffedLC := GetAeonLC()
+	unbuffedLC.Buffs = nil
+	unbuffedRB := GetHookRelicBuild()
+	unbuffedRB.SetEffects = nil
+	numbyDmg, _, err = hsrtct.CalcAvgDamage(unbuffedHook, unbuffedLC, unbuffedRB, GetBasicEnemy(), numbyAttack, false)
+	assertNilError(t, err)
+	if math.Abs(result.SummonDmg["Numby"]-numbyDmg*4) > 1e-6 {
+		t.Fatalf("Expected unbuffed Numby damage to be %v, got %v", numbyDmg*4, result.SummonDmg["Numby"])
+	}
+}
+
+func TestCalcAvgDmgScenarioSummonTurns(t *testing.T) {
+	hook := GetHookCharacter()
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	numbyAttack := hsrtct.Attack{Name: "Numby", ScalingStat: hsrtct.Atk, Multiplier: 150, DamageTags: hsrtct.DamageTags{hsrtct.FollowUp}}
+	numby := hsrtct.Summon{
+		Name:         "Numby",
+		Spd:          80,
+		RotationAV:   200,
+		Attacks:      map[*hsrtct.Attack]float64{&numbyAttack: 1},
+		InheritBuffs: true,
+	}
+	scn := hsrtct.Scenario{
+		Character:  hook,
+		LightCone:  lc,
+		RelicBuild: rb,
+		Enemies:    []hsrtct.Enemy{GetBasicEnemy()},
+		Summons:    []hsrtct.Summon{numby},
+	}
+	result, err := hsrtct.CalcAvgDmgScenario(scn)
+	assertNilError(t, err)
+
+	// 200 AV at 80 SPD are 1.6 turns
+	numbyDmg, _, err := hsrtct.CalcAvgDamage(hook, lc, rb, GetBasicEnemy(), numbyAttack, false)
+	assertNilError(t, err)
+	if math.Abs(result.SummonDmg["Numby"]-numbyDmg*1.6) > 1e-6 {
+		t.Fatalf("Expected Numby damage to be %v, got %v", numbyDmg*1.6, result.SummonDmg["Numby"])
+	}
+
+	scn.Summons[0].Spd = 0
+	_, err = hsrtct.CalcAvgDmgScenario(scn)
+	if !errors.Is(err, hsrtct.ErrInvalidSpd) {
+		t.Fatalf("Expected ErrInvalidSpd, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package hsrtct
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidTeamSize = errors.New("invalid team size")
+
+const MaxTeamSize = 4
+
+// TeamMember is a character of a TeamScenario with its own build and attacks.
+// Buffs only apply to this member, TeamBuffs are given by this member to the whole team (including itself).
+type TeamMember struct {
+	Character       Character
+	LightCone       LightCone
+	RelicBuild      RelicBuild
+	Attacks         map[*Attack]float64
+	StackOverrides  map[*Attack]map[string]int
+	Energy          EnergyModel
+	DeriveUltimates bool
+	Summons         []Summon
+	Buffs           []Buff
+	TeamBuffs       []Buff
+}
+
+// TeamScenario is a scenario with up to MaxTeamSize characters attacking the same enemies.
+// TeamBuffs apply to every member, on top of the members' own TeamBuffs.
+type TeamScenario struct {
+	ID           uint64
+	Name         string
+	Notes        string
+	Members      []TeamMember
+	Enemies      []Enemy
+	FocusedEnemy int
+	UptimeMode   UptimeMode
+	TeamBuffs    []Buff
+}
+
+// TeamResult has the totals of the team and the result of each member, indexed like TeamScenario.Members
+type TeamResult struct {
+	TotalDmg    float64
+	MinDmg      float64
+	MaxDmg      float64
+	DmgVariance float64
+	Members     []ScenarioResult
+}
+
+// MemberScenario returns the Scenario of a single member, with every team buff and the member's own buffs on its Character
+func (t TeamScenario) MemberScenario(index int) Scenario {
+	member := t.Members[index]
+	character := member.Character
+	buffs := make([]Buff, 0, len(character.Buffs)+len(member.Buffs)+len(t.TeamBuffs))
+	buffs = append(buffs, character.Buffs...)
+	buffs = append(buffs, member.Buffs...)
+	buffs = append(buffs, t.TeamBuffs...)
+	for _, teammate := range t.Members {
+		buffs = append(buffs, teammate.TeamBuffs...)
+	}
+	character.Buffs = buffs
+
+	return Scenario{
+		ID:              t.ID,
+		Name:            fmt.Sprintf("%s (%s)", t.Name, character.Name),
+		Notes:           t.Notes,
+		Character:       character,
+		LightCone:       member.LightCone,
+		RelicBuild:      member.RelicBuild,
+		Enemies:         t.Enemies,
+		FocusedEnemy:    t.FocusedEnemy,
+		Attacks:         member.Attacks,
+		StackOverrides:  member.StackOverrides,
+		UptimeMode:      t.UptimeMode,
+		Energy:          member.Energy,
+		DeriveUltimates: member.DeriveUltimates,
+		Summons:         member.Summons,
+	}
+}
+
+// MemberBuffSource returns a member as a BuffSource, with every team buff and the member's own buffs on its Character
+func (t TeamScenario) MemberBuffSource(index int) *BuffSource {
+	scn := t.MemberScenario(index)
+	return &BuffSource{Character: scn.Character, LightCone: scn.LightCone, RelicBuild: scn.RelicBuild}
+}
+
+func CalcAvgDmgTeamScenario(t TeamScenario) (TeamResult, error) {
+	if len(t.Members) == 0 || len(t.Members) > MaxTeamSize {
+		return TeamResult{}, fmt.Errorf("%w: %d (max %d)", ErrInvalidTeamSize, len(t.Members), MaxTeamSize)
+	}
+
+	result := TeamResult{Members: make([]ScenarioResult, len(t.Members))}
+	for i, member := range t.Members {
+		memberResult, err := CalcAvgDmgScenario(t.MemberScenario(i))
+		if err != nil {
+			return TeamResult{}, fmt.Errorf("%s: %w", member.Character.Name, err)
+		}
+		result.Members[i] = memberResult
+		result.TotalDmg += memberResult.TotalDmg
+		result.MinDmg += memberResult.MinDmg
+		result.MaxDmg += memberResult.MaxDmg
+		result.DmgVariance += memberResult.DmgVariance
+	}
+	return result, nil
+}
+
+// CalcTeamSpBalance returns the skill points generated minus the skill points spent per rotation by the whole team
+func CalcTeamSpBalance(t TeamScenario) float64 {
+	balance := 0.0
+	for i := range t.Members {
+		balance += CalcSpBalance(t.MemberScenario(i))
+	}
+	return balance
+}
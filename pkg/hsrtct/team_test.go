@@ -0,0 +1,75 @@
+package hsrtct_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/j4rv/hsr-tct/pkg/hsrtct"
+)
+
+func TestCalcAvgDmgTeamScenario(t *testing.T) {
+	hookSkill := hsrtct.Attack{Name: "Skill", ScalingStat: hsrtct.Atk, Multiplier: 200, DamageTags: hsrtct.DamageTags{hsrtct.Skill}, SpCost: 1}
+	supportBasic := hsrtct.Attack{Name: "Basic", ScalingStat: hsrtct.Atk, Multiplier: 100, DamageTags: hsrtct.DamageTags{hsrtct.Basic}, SpGain: 1}
+	support := GetHookCharacter()
+	support.Name = "Support"
+
+	team := hsrtct.TeamScenario{
+		Name:      "Team",
+		Enemies:   []hsrtct.Enemy{GetBasicEnemy()},
+		TeamBuffs: []hsrtct.Buff{{Stat: hsrtct.AtkPct, Value: 20}},
+		Members: []hsrtct.TeamMember{
+			{
+				Character:  GetHookCharacter(),
+				LightCone:  GetAeonLC(),
+				RelicBuild: GetHookRelicBuild(),
+				Attacks:    map[*hsrtct.Attack]float64{&hookSkill: 3},
+				Buffs:      []hsrtct.Buff{{Stat: hsrtct.CritDmg, Value: 30}},
+			},
+			{
+				Character:  support,
+				LightCone:  GetAeonLC(),
+				RelicBuild: GetHookRelicBuild(),
+				Attacks:    map[*hsrtct.Attack]float64{&supportBasic: 2},
+				TeamBuffs:  []hsrtct.Buff{{Stat: hsrtct.DmgBonus, Value: 10}},
+			},
+		},
+	}
+	result, err := hsrtct.CalcAvgDmgTeamScenario(team)
+	assertNilError(t, err)
+
+	hook := GetHookCharacter()
+	hook.Buffs = append(hook.Buffs, hsrtct.Buff{Stat: hsrtct.CritDmg, Value: 30}, hsrtct.Buff{Stat: hsrtct.AtkPct, Value: 20}, hsrtct.Buff{Stat: hsrtct.DmgBonus, Value: 10})
+	hookDmg, _, err := hsrtct.CalcAvgDamage(hook, GetAeonLC(), GetHookRelicBuild(), GetBasicEnemy(), hookSkill, false)
+	assertNilError(t, err)
+	support.Buffs = append(support.Buffs, hsrtct.Buff{Stat: hsrtct.AtkPct, Value: 20}, hsrtct.Buff{Stat: hsrtct.DmgBonus, Value: 10})
+	supportDmg, _, err := hsrtct.CalcAvgDamage(support, GetAeonLC(), GetHookRelicBuild(), GetBasicEnemy(), supportBasic, false)
+	assertNilError(t, err)
+
+	if math.Abs(result.Members[0].TotalDmg-hookDmg*3) > 1e-6 {
+		t.Fatalf("Expected Hook damage to be %v, got %v", hookDmg*3, result.Members[0].TotalDmg)
+	}
+	if math.Abs(result.Members[1].TotalDmg-supportDmg*2) > 1e-6 {
+		t.Fatalf("Expected Support damage to be %v, got %v", supportDmg*2, result.Members[1].TotalDmg)
+	}
+	if math.Abs(result.TotalDmg-hookDmg*3-supportDmg*2) > 1e-6 {
+		t.Fatalf("Expected team damage to be %v, got %v", hookDmg*3+supportDmg*2, result.TotalDmg)
+	}
+	if balance := hsrtct.CalcTeamSpBalance(team); balance != -1 {
+		t.Fatalf("Expected a team SP balance of -1, got %v", balance)
+	}
+	if len(team.Members[0].Character.Buffs) != len(GetHookCharacter().Buffs) {
+		t.Fatalf("Expected the member's character to be left untouched")
+	}
+}
+
+func TestCalcAvgDmgTeamScenarioSize(t *testing.T) {
+	_, err := hsrtct.CalcAvgDmgTeamScenario(hsrtct.TeamScenario{})
+	if !errors.Is(err, hsrtct.ErrInvalidTeamSize) {
+		t.Fatalf("Expected ErrInvalidTeamSize, got %v", err)
+	}
+	_, err = hsrtct.CalcAvgDmgTeamScenario(hsrtct.TeamScenario{Members: make([]hsrtct.TeamMember, 5)})
+	if !errors.Is(err, hsrtct.ErrInvalidTeamSize) {
+		t.Fatalf("Expected ErrInvalidTeamSize, got %v", err)
+	}
+}
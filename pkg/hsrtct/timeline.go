@@ -0,0 +1,263 @@
+package hsrtct
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidSpd = errors.New("invalid speed")
+var ErrUnknownUnit = errors.New("unknown timeline unit")
+var ErrTimelineStalled = errors.New("timeline does not advance")
+
+// A unit acts after advancing ActionGauge, so its action value (AV) is ActionGauge / Spd
+const ActionGauge = 10000.0
+
+// Turns each unit can take in a row without any AV passing, more than that means some effects advance the units forever
+const MaxTurnsWithoutAV = 100
+
+// Memory of Chaos cycles, the first one (the 0-cycle) lasts FirstCycleAV and the next ones CycleAV
+const FirstCycleAV = 150.0
+const CycleAV = 100.0
+
+// ActionValue returns the AV a unit with the given speed needs to act
+func ActionValue(spd float64) float64 {
+	return ActionGauge / spd
+}
+
+// CyclesAV returns the AV of the 0-cycle plus the given amount of cycles
+func CyclesAV(cycles int) float64 {
+	return FirstCycleAV + CycleAV*float64(cycles)
+}
+
+// TimelineEffect is triggered by an action of the unit that has it.
+// Turn is the turn of the unit that triggers it, starting at 1, zero triggers it on every turn.
+// Action is the action that triggers it, empty means any action (including ultimates).
+// Target is the name of the affected unit, empty means the unit itself.
+// Advance is the action advance in %, negative values delay the target.
+// Spd and SpdPct buff the target's speed for Duration of the target's turns, a zero Duration lasts forever.
+type TimelineEffect struct {
+	Turn     int       `json:"turn"`
+	Action   DamageTag `json:"action"`
+	Target   string    `json:"target"`
+	Advance  float64   `json:"advance"`
+	Spd      float64   `json:"spd"`
+	SpdPct   float64   `json:"spdPct"`
+	Duration int       `json:"duration"`
+}
+
+// TimelineUnit is a unit acting in a Timeline.
+// Spd is its speed without the timeline's speed buffs, SpdPct buffs scale BaseSpd.
+// Actions is the action of each of its turns, repeated in order, an empty Actions always uses its Skill.
+// The unit uses its ultimate after every UltimateEvery turns, zero means it never does.
+type TimelineUnit struct {
+	Name          string           `json:"name"`
+	BaseSpd       float64          `json:"baseSpd"`
+	Spd           float64          `json:"spd"`
+	Actions       []DamageTag      `json:"actions"`
+	UltimateEvery int              `json:"ultimateEvery"`
+	Effects       []TimelineEffect `json:"effects"`
+}
+
+// NewTimelineUnit returns a timeline unit with the character's final speed, buffs restricted to some attacks are ignored
+func NewTimelineUnit(c Character, lc LightCone, rb RelicBuild, actions []DamageTag) TimelineUnit {
+	return TimelineUnit{
+		Name:    c.Name,
+		BaseSpd: c.BaseSpd,
+		Spd:     c.FinalStatValue(lc, rb, Spd, DamageTags{NonAttack}, AnyElement, nil),
+		Actions: actions,
+	}
+}
+
+// Timeline simulates the turn order of its units during the 0-cycle plus Cycles cycles
+type Timeline struct {
+	Units  []TimelineUnit `json:"units"`
+	Cycles int            `json:"cycles"`
+}
+
+// TimelineTurn is an action that happened in the timeline, AV is the elapsed action value when it happened
+type TimelineTurn struct {
+	Unit   string
+	AV     float64
+	Action DamageTag
+	Spd    float64
+}
+
+type TimelineResult struct {
+	// Total AV of the timeline
+	AV    float64
+	Turns []TimelineTurn
+	// Amount of each action used by each unit, by unit name
+	Actions map[string]map[DamageTag]int
+}
+
+// ScenarioAttacks returns the Scenario attack amounts of the unit, using the given attack for each action
+func (r TimelineResult) ScenarioAttacks(unit string, attacks map[DamageTag]*Attack) map[*Attack]float64 {
+	result := make(map[*Attack]float64)
+	for action, amount := range r.Actions[unit] {
+		if attack, ok := attacks[action]; ok && amount > 0 {
+			result[attack] += float64(amount)
+		}
+	}
+	return result
+}
+
+type timelineSpdBuff struct {
+	spd       float64
+	spdPct    float64
+	turnsLeft int
+}
+
+type timelineState struct {
+	unit    TimelineUnit
+	turn    int
+	av      float64
+	spdBuff []timelineSpdBuff
+}
+
+func (s *timelineState) spd() float64 {
+	spd := s.unit.Spd
+	for _, buff := range s.spdBuff {
+		spd += buff.spd + s.unit.BaseSpd*buff.spdPct/100
+	}
+	return spd
+}
+
+// validateSpd returns ErrInvalidSpd if the speed buffs brought the unit's speed to zero or below
+func (s *timelineState) validateSpd() error {
+	if spd := s.spd(); spd <= 0 {
+		return fmt.Errorf("%w: %s has %.2f speed", ErrInvalidSpd, s.unit.Name, spd)
+	}
+	return nil
+}
+
+// updateSpd applies a change in speed keeping the distance left to the unit's turn
+func (s *timelineState) updateSpd(update func()) error {
+	oldSpd := s.spd()
+	update()
+	if err := s.validateSpd(); err != nil {
+		return err
+	}
+	s.av *= oldSpd / s.spd()
+	return nil
+}
+
+// SimulateTimeline calculates the turn order of the timeline's units.
+// Ties are resolved by the order of the units.
+func SimulateTimeline(t Timeline) (TimelineResult, error) {
+	states := make([]*timelineState, len(t.Units))
+	byName := make(map[string]*timelineState, len(t.Units))
+	for i, unit := range t.Units {
+		if unit.Spd <= 0 {
+			return TimelineResult{}, fmt.Errorf("%w: %s has %.2f speed", ErrInvalidSpd, unit.Name, unit.Spd)
+		}
+		states[i] = &timelineState{unit: unit, av: ActionValue(unit.Spd)}
+		byName[unit.Name] = states[i]
+	}
+	for _, unit := range t.Units {
+		for _, effect := range unit.Effects {
+			if _, ok := byName[effect.Target]; effect.Target != "" && !ok {
+				return TimelineResult{}, fmt.Errorf("%w: %s", ErrUnknownUnit, effect.Target)
+			}
+		}
+	}
+
+	result := TimelineResult{
+		AV:      CyclesAV(t.Cycles),
+		Actions: make(map[string]map[DamageTag]int, len(t.Units)),
+	}
+	for _, unit := range t.Units {
+		result.Actions[unit.Name] = make(map[DamageTag]int)
+	}
+
+	applyEffects := func(source *timelineState, action DamageTag) error {
+		for _, effect := range source.unit.Effects {
+			if (effect.Turn != 0 && effect.Turn != source.turn) || (effect.Action != "" && effect.Action != action) {
+				continue
+			}
+			target := source
+			if effect.Target != "" {
+				target = byName[effect.Target]
+			}
+			if effect.Spd != 0 || effect.SpdPct != 0 {
+				err := target.updateSpd(func() {
+					target.spdBuff = append(target.spdBuff, timelineSpdBuff{spd: effect.Spd, spdPct: effect.SpdPct, turnsLeft: effect.Duration})
+				})
+				if err != nil {
+					return err
+				}
+			}
+			target.av -= effect.Advance / 100 * ActionValue(target.spd())
+			if target.av < 0 {
+				target.av = 0
+			}
+		}
+		return nil
+	}
+
+	elapsed := 0.0
+	turnsWithoutAV := 0
+	for {
+		var next *timelineState
+		for _, state := range states {
+			if next == nil || state.av < next.av {
+				next = state
+			}
+		}
+		if next == nil || elapsed+next.av > result.AV {
+			break
+		}
+
+		passed := next.av
+		if passed > 0 {
+			turnsWithoutAV = 0
+		} else {
+			turnsWithoutAV++
+		}
+		if turnsWithoutAV > MaxTurnsWithoutAV*len(states) {
+			return TimelineResult{}, fmt.Errorf("%w: %d turns at %.2f AV", ErrTimelineStalled, turnsWithoutAV, elapsed)
+		}
+		elapsed += passed
+		for _, state := range states {
+			state.av -= passed
+		}
+
+		next.turn++
+		action := Skill
+		if len(next.unit.Actions) > 0 {
+			action = next.unit.Actions[(next.turn-1)%len(next.unit.Actions)]
+		}
+		result.Turns = append(result.Turns, TimelineTurn{Unit: next.unit.Name, AV: elapsed, Action: action, Spd: next.spd()})
+		result.Actions[next.unit.Name][action]++
+
+		// Speed buffs expire at the end of their holder's turns, buffs applied during this turn are not counted
+		buffs := next.spdBuff[:0]
+		for _, buff := range next.spdBuff {
+			if buff.turnsLeft == 0 {
+				buffs = append(buffs, buff)
+				continue
+			}
+			buff.turnsLeft--
+			if buff.turnsLeft > 0 {
+				buffs = append(buffs, buff)
+			}
+		}
+		next.spdBuff = buffs
+		if err := next.validateSpd(); err != nil {
+			return TimelineResult{}, err
+		}
+		next.av = ActionValue(next.spd())
+		if err := applyEffects(next, action); err != nil {
+			return TimelineResult{}, err
+		}
+
+		if next.unit.UltimateEvery > 0 && next.turn%next.unit.UltimateEvery == 0 {
+			result.Turns = append(result.Turns, TimelineTurn{Unit: next.unit.Name, AV: elapsed, Action: Ultimate, Spd: next.spd()})
+			result.Actions[next.unit.Name][Ultimate]++
+			if err := applyEffects(next, Ultimate); err != nil {
+				return TimelineResult{}, err
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,131 @@
+package hsrtct_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/j4rv/hsr-tct/pkg/hsrtct"
+)
+
+func TestSimulateTimeline(t *testing.T) {
+	timeline := hsrtct.Timeline{
+		Cycles: 2,
+		Units: []hsrtct.TimelineUnit{
+			{Name: "Slow", BaseSpd: 100, Spd: 100, Actions: []hsrtct.DamageTag{hsrtct.Skill, hsrtct.Basic}},
+			{Name: "Fast", BaseSpd: 100, Spd: 134, UltimateEvery: 2},
+		},
+	}
+	result, err := hsrtct.SimulateTimeline(timeline)
+	assertNilError(t, err)
+
+	if result.AV != 350 {
+		t.Fatalf("Expected 350 AV, got %v", result.AV)
+	}
+	// 100 speed acts at 100, 200 and 300 AV
+	slow := result.Actions["Slow"]
+	if slow[hsrtct.Skill] != 2 || slow[hsrtct.Basic] != 1 {
+		t.Fatalf("Expected 2 skills and 1 basic, got %v", slow)
+	}
+	// 134 speed acts every 74.63 AV, 4 times in 350 AV
+	fast := result.Actions["Fast"]
+	if fast[hsrtct.Skill] != 4 || fast[hsrtct.Ultimate] != 2 {
+		t.Fatalf("Expected 4 skills and 2 ultimates, got %v", fast)
+	}
+	if math.Abs(result.Turns[0].AV-hsrtct.ActionValue(134)) > 1e-9 || result.Turns[0].Unit != "Fast" {
+		t.Fatalf("Expected Fast to act first at %v AV, got %+v", hsrtct.ActionValue(134), result.Turns[0])
+	}
+}
+
+func TestSimulateTimelineAdvanceAndSpdBuffs(t *testing.T) {
+	timeline := hsrtct.Timeline{
+		Cycles: 1,
+		Units: []hsrtct.TimelineUnit{
+			{
+				Name: "Support", BaseSpd: 100, Spd: 100,
+				Effects: []hsrtct.TimelineEffect{{Target: "Dps", Advance: 100}},
+			},
+			{
+				Name: "Dps", BaseSpd: 100, Spd: 100,
+				Effects: []hsrtct.TimelineEffect{{Turn: 1, SpdPct: 25, Duration: 1}},
+			},
+		},
+	}
+	result, err := hsrtct.SimulateTimeline(timeline)
+	assertNilError(t, err)
+
+	// Dps buffs itself for its next turn at 100 + 10000/125 AV, then the Support's
+	// second turn at 200 AV pulls Dps forward instead of acting at 180 + 100
+	expectedTurns := []hsrtct.TimelineTurn{
+		{Unit: "Support", AV: 100, Action: hsrtct.Skill, Spd: 100},
+		{Unit: "Dps", AV: 100, Action: hsrtct.Skill, Spd: 100},
+		{Unit: "Dps", AV: 180, Action: hsrtct.Skill, Spd: 125},
+		{Unit: "Support", AV: 200, Action: hsrtct.Skill, Spd: 100},
+		{Unit: "Dps", AV: 200, Action: hsrtct.Skill, Spd: 100},
+	}
+	if len(result.Turns) != len(expectedTurns) {
+		t.Fatalf("Expected turns %+v, got %+v", expectedTurns, result.Turns)
+	}
+	for i, turn := range result.Turns {
+		expected := expectedTurns[i]
+		if turn.Unit != expected.Unit || math.Abs(turn.AV-expected.AV) > 1e-9 || turn.Action != expected.Action || turn.Spd != expected.Spd {
+			t.Fatalf("Expected turn %d to be %+v, got %+v", i, expected, turn)
+		}
+	}
+
+	basic := hsrtct.Attack{Name: "Skill"}
+	attacks := result.ScenarioAttacks("Dps", map[hsrtct.DamageTag]*hsrtct.Attack{hsrtct.Skill: &basic})
+	if attacks[&basic] != 3 {
+		t.Fatalf("Expected 3 skills, got %v", attacks)
+	}
+}
+
+func TestSimulateTimelineErrors(t *testing.T) {
+	_, err := hsrtct.SimulateTimeline(hsrtct.Timeline{Units: []hsrtct.TimelineUnit{{Name: "Frozen"}}})
+	if !errors.Is(err, hsrtct.ErrInvalidSpd) {
+		t.Fatalf("Expected ErrInvalidSpd, got %v", err)
+	}
+	_, err = hsrtct.SimulateTimeline(hsrtct.Timeline{Units: []hsrtct.TimelineUnit{
+		{Name: "A", Spd: 100, Effects: []hsrtct.TimelineEffect{{Target: "B", Advance: 50}}},
+	}})
+	if !errors.Is(err, hsrtct.ErrUnknownUnit) {
+		t.Fatalf("Expected ErrUnknownUnit, got %v", err)
+	}
+}
+
+func TestSimulateTimelineNegativeSpd(t *testing.T) {
+	_, err := hsrtct.SimulateTimeline(hsrtct.Timeline{Units: []hsrtct.TimelineUnit{
+		{Name: "A", Spd: 100, Effects: []hsrtct.TimelineEffect{{Turn: 1, Spd: -150}}},
+		{Name: "B", Spd: 100},
+	}})
+	if !errors.Is(err, hsrtct.ErrInvalidSpd) {
+		t.Fatalf("Expected ErrInvalidSpd, got %v", err)
+	}
+}
+
+func TestSimulateTimelineStalled(t *testing.T) {
+	_, err := hsrtct.SimulateTimeline(hsrtct.Timeline{Units: []hsrtct.TimelineUnit{
+		{Name: "A", Spd: 100, Effects: []hsrtct.TimelineEffect{{Advance: 100}}},
+	}})
+	if !errors.Is(err, hsrtct.ErrTimelineStalled) {
+		t.Fatalf("Expected ErrTimelineStalled, got %v", err)
+	}
+	_, err = hsrtct.SimulateTimeline(hsrtct.Timeline{Units: []hsrtct.TimelineUnit{
+		{Name: "A", Spd: 100, Effects: []hsrtct.TimelineEffect{{Target: "B", Advance: 100}}},
+		{Name: "B", Spd: 100, Effects: []hsrtct.TimelineEffect{{Target: "A", Advance: 100}}},
+	}})
+	if !errors.Is(err, hsrtct.ErrTimelineStalled) {
+		t.Fatalf("Expected ErrTimelineStalled, got %v", err)
+	}
+}
+
+func TestNewTimelineUnitIgnoresTaggedBuffs(t *testing.T) {
+	hook := GetHookCharacter()
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	spd := hsrtct.NewTimelineUnit(hook, lc, rb, nil).Spd
+	hook.Buffs = append(hook.Buffs, hsrtct.Buff{Stat: hsrtct.Spd, Value: 20, DamageTag: hsrtct.Skill})
+	if unit := hsrtct.NewTimelineUnit(hook, lc, rb, nil); unit.Spd != spd {
+		t.Fatalf("Expected %v speed, got %v", spd, unit.Spd)
+	}
+}
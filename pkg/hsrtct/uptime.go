@@ -0,0 +1,90 @@
+package hsrtct
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrTooManyUptimeBuffs = errors.New("too many buffs with partial uptime")
+
+// Max amount of buffs with partial uptime for UptimeExact, each one doubles the calculations
+const maxExactUptimeBuffs = 10
+
+// UptimeMode is how buffs with partial uptime are taken into account.
+// UptimeExact calculates the damage with every combination of active and inactive buffs, and weights it by its probability.
+// UptimeLinear scales the value of each buff by its uptime, which is faster but not exact for buffs with
+// non linear effects, like Crit Rate over the cap or DEF reduction.
+type UptimeMode string
+
+const (
+	UptimeExact  UptimeMode = ""
+	UptimeLinear UptimeMode = "Linear"
+)
+
+type hitCalc func(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack) (float64, string, error)
+
+// weightByUptime calculates the expected value of calc, taking into account the buffs with partial uptime.
+// Uptimes are assumed to be independent of each other.
+func weightByUptime(c Character, lc LightCone, rb RelicBuild, e Enemy, a Attack, mode UptimeMode, calc hitCalc) (float64, string, error) {
+	partialBuffs := 0
+	for _, buffs := range [][]Buff{c.Buffs, lc.Buffs, rb.SetEffects, a.Buffs, e.Buffs} {
+		for _, buff := range buffs {
+			if buff.HasPartialUptime() {
+				partialBuffs++
+			}
+		}
+	}
+	if mode == UptimeLinear || partialBuffs == 0 {
+		return calc(c, lc, rb, e, a)
+	}
+	if partialBuffs > maxExactUptimeBuffs {
+		return 0, "", fmt.Errorf("%w: %d (max %d)", ErrTooManyUptimeBuffs, partialBuffs, maxExactUptimeBuffs)
+	}
+
+	_, explanation, err := calc(c, lc, rb, e, a)
+	if err != nil {
+		return 0, "", err
+	}
+
+	expected := 0.0
+	combinations := 1 << partialBuffs
+	for mask := 0; mask < combinations; mask++ {
+		bit := 0
+		weight := 1.0
+		toggle := func(buffs []Buff) []Buff {
+			result := make([]Buff, 0, len(buffs))
+			for _, buff := range buffs {
+				if !buff.HasPartialUptime() {
+					result = append(result, buff)
+					continue
+				}
+				active := mask&(1<<bit) != 0
+				bit++
+				if active {
+					weight *= buff.Uptime
+					buff.Uptime = 0
+					result = append(result, buff)
+				} else {
+					weight *= 1 - buff.Uptime
+				}
+			}
+			return result
+		}
+
+		cc, lcc, rbc, ec, ac := c, lc, rb, e, a
+		cc.Buffs = toggle(c.Buffs)
+		lcc.Buffs = toggle(lc.Buffs)
+		rbc.SetEffects = toggle(rb.SetEffects)
+		ac.Buffs = toggle(a.Buffs)
+		ec.Buffs = toggle(e.Buffs)
+
+		value, _, err := calc(cc, lcc, rbc, ec, ac)
+		if err != nil {
+			return 0, "", err
+		}
+		expected += value * weight
+	}
+
+	explanation = fmt.Sprintf("Weighted over %d uptime combinations, the multipliers below use average buff values\n", combinations) + explanation
+	return expected, explanation, nil
+}
@@ -0,0 +1,73 @@
+package hsrtct_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/j4rv/hsr-tct/pkg/hsrtct"
+)
+
+func TestCalcAvgDamageUptime(t *testing.T) {
+	lc := GetAeonLC()
+	rb := GetHookRelicBuild()
+	enemy := GetBasicEnemy()
+	attack := hsrtct.Attack{
+		ScalingStat: hsrtct.Atk,
+		Multiplier:  100,
+		Element:     hsrtct.Fire,
+		DamageTags:  hsrtct.DamageTags{hsrtct.Skill},
+	}
+	atkBuff := hsrtct.Buff{Stat: hsrtct.AtkPct, Value: 50}
+	// Crit Rate over the cap makes the damage non linear
+	critBuff := hsrtct.Buff{Stat: hsrtct.CritRate, Value: 50}
+
+	// Expected damage with both buffs active half of the time, independently
+	expected := 0.0
+	for _, buffs := range [][]hsrtct.Buff{{}, {atkBuff}, {critBuff}, {atkBuff, critBuff}} {
+		hook := GetHookCharacter()
+		hook.Buffs = append(hook.Buffs, buffs...)
+		dmg, _, err := hsrtct.CalcAvgDamage(hook, lc, rb, enemy, attack, false)
+		assertNilError(t, err)
+		expected += dmg / 4
+	}
+
+	atkBuff.Uptime = 0.5
+	critBuff.Uptime = 0.5
+	hook := GetHookCharacter()
+	hook.Buffs = append(hook.Buffs, atkBuff, critBuff)
+	scn := hsrtct.Scenario{
+		Character:  hook,
+		LightCone:  lc,
+		RelicBuild: rb,
+		Enemies:    []hsrtct.Enemy{enemy},
+		Attacks:    map[*hsrtct.Attack]float64{&attack: 1},
+	}
+
+	exact, err := hsrtct.CalcAvgDmgScenario(scn)
+	assertNilError(t, err)
+	if fmt.Sprintf("%.4f", exact.TotalDmg) != fmt.Sprintf("%.4f", expected) {
+		t.Fatalf("Expected exact damage to be %v, got %v", expected, exact.TotalDmg)
+	}
+
+	scn.UptimeMode = hsrtct.UptimeLinear
+	linear, err := hsrtct.CalcAvgDmgScenario(scn)
+	assertNilError(t, err)
+	if linear.TotalDmg <= exact.TotalDmg {
+		t.Fatalf("Expected the linear approximation to overestimate the damage, got %v and %v", linear.TotalDmg, exact.TotalDmg)
+	}
+}
+
+func TestInvalidUptime(t *testing.T) {
+	for _, uptime := range []float64{-0.5, 2} {
+		buff := hsrtct.Buff{Stat: hsrtct.AtkPct, Value: 10, Uptime: uptime}
+		if err := buff.Validate(); !errors.Is(err, hsrtct.ErrInvalidUptime) {
+			t.Fatalf("Expected ErrInvalidUptime for %v uptime, got %v", uptime, err)
+		}
+		attack := hsrtct.Attack{ScalingStat: hsrtct.Atk, Multiplier: 100, Buffs: []hsrtct.Buff{buff}}
+		_, _, err := hsrtct.CalcAvgDamage(GetHookCharacter(), GetAeonLC(), GetHookRelicBuild(), GetBasicEnemy(), attack, false)
+		if !errors.Is(err, hsrtct.ErrInvalidUptime) {
+			t.Fatalf("Expected CalcAvgDamage to return ErrInvalidUptime for %v uptime, got %v", uptime, err)
+		}
+	}
+}